@@ -19,9 +19,13 @@ func main() {
 		logger.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	// Initialize logger with configured level
-	logger.Init(cfg.LogLevel)
-	logger.Debugf("Logger initialized with level: %s", cfg.LogLevel)
+	// Initialize logger with configured level, format and output
+	output, err := logger.OpenOutput(cfg.LogOutput)
+	if err != nil {
+		logger.Fatalf("Failed to configure logging: %v", err)
+	}
+	logger.Setup(logger.Options{Level: cfg.LogLevel, Format: cfg.LogFormat, Output: output})
+	logger.Debugf("Logger initialized with level: %s, format: %s", cfg.LogLevel, cfg.LogFormat)
 
 	// Create and start server
 	srv, err := server.New(cfg)
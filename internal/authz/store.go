@@ -0,0 +1,154 @@
+package authz
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// RequestURIPrefix is the URN prefix of pushed authorization request URIs (RFC 9126 section 2.2)
+const RequestURIPrefix = "urn:ietf:params:oauth:request_uri:"
+
+// PushedRequest is an authorization request pushed to the PAR endpoint
+type PushedRequest struct {
+	RequestURI string
+	ClientID   string
+	Params     url.Values
+	Signed     bool // the parameters came from a verified request object
+	ExpiresAt  time.Time
+}
+
+// Code is an issued authorization code and the request it was issued for
+type Code struct {
+	Code                string
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	Nonce               string
+	Subject             string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	ExpiresAt           time.Time
+}
+
+// Store keeps pushed authorization requests and authorization codes in memory.
+// Both are single use.
+type Store struct {
+	requests map[string]*PushedRequest
+	codes    map[string]*Code
+	mu       sync.Mutex // Protect concurrent access to requests and codes
+}
+
+// NewStore creates a new authorization store
+func NewStore() *Store {
+	return &Store{
+		requests: make(map[string]*PushedRequest),
+		codes:    make(map[string]*Code),
+	}
+}
+
+// randomToken returns a random URL-safe string
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random value: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Push stores an authorization request and returns its request URI
+func (s *Store) Push(clientID string, params url.Values, signed bool, ttl time.Duration) (*PushedRequest, error) {
+	value, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	request := &PushedRequest{
+		RequestURI: RequestURIPrefix + value,
+		ClientID:   clientID,
+		Params:     params,
+		Signed:     signed,
+		ExpiresAt:  time.Now().Add(ttl),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeExpired()
+	s.requests[request.RequestURI] = request
+	return request, nil
+}
+
+// TakeRequest consumes a pushed authorization request made by clientID
+func (s *Store) TakeRequest(requestURI, clientID string) (*PushedRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	request, ok := s.requests[requestURI]
+	if !ok {
+		return nil, fmt.Errorf("unknown or already used request_uri")
+	}
+
+	// Another client presenting the URI must not consume it for its owner
+	if request.ClientID != clientID {
+		return nil, fmt.Errorf("request_uri was issued to a different client")
+	}
+	if time.Now().After(request.ExpiresAt) {
+		return nil, fmt.Errorf("request_uri has expired")
+	}
+	delete(s.requests, requestURI)
+	return request, nil
+}
+
+// IssueCode stores an authorization code with the given lifetime and returns the code value
+func (s *Store) IssueCode(code Code, ttl time.Duration) (string, error) {
+	value, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	code.Code = value
+	code.ExpiresAt = time.Now().Add(ttl)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeExpired()
+	s.codes[value] = &code
+	return value, nil
+}
+
+// RedeemCode consumes an authorization code
+func (s *Store) RedeemCode(value string) (*Code, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	code, ok := s.codes[value]
+	if !ok {
+		return nil, fmt.Errorf("unknown or already used authorization code")
+	}
+	delete(s.codes, value)
+
+	if time.Now().After(code.ExpiresAt) {
+		return nil, fmt.Errorf("authorization code has expired")
+	}
+	return code, nil
+}
+
+// removeExpired drops expired requests and codes. Callers must hold the lock.
+func (s *Store) removeExpired() {
+	now := time.Now()
+	for key, request := range s.requests {
+		if now.After(request.ExpiresAt) {
+			delete(s.requests, key)
+		}
+	}
+	for key, code := range s.codes {
+		if now.After(code.ExpiresAt) {
+			delete(s.codes, key)
+		}
+	}
+}
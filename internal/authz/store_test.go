@@ -0,0 +1,77 @@
+package authz
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTakeRequest(t *testing.T) {
+	store := NewStore()
+
+	pushed, err := store.Push("client-a", url.Values{"scope": {"openid"}}, true, time.Minute)
+	if err != nil {
+		t.Fatalf("Push() unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(pushed.RequestURI, RequestURIPrefix) {
+		t.Errorf("Push() request URI = %q, want prefix %q", pushed.RequestURI, RequestURIPrefix)
+	}
+
+	request, err := store.TakeRequest(pushed.RequestURI, "client-a")
+	if err != nil {
+		t.Fatalf("TakeRequest() unexpected error: %v", err)
+	}
+	if request.Params.Get("scope") != "openid" || !request.Signed {
+		t.Errorf("TakeRequest() = %+v, want the pushed signed request", request)
+	}
+
+	// Request URIs are single use
+	if _, err := store.TakeRequest(pushed.RequestURI, "client-a"); err == nil {
+		t.Error("TakeRequest() second use expected error")
+	}
+}
+
+func TestTakeRequestRejectsOtherClientsAndExpired(t *testing.T) {
+	store := NewStore()
+
+	pushed, _ := store.Push("client-a", url.Values{}, false, time.Minute)
+	if _, err := store.TakeRequest(pushed.RequestURI, "client-b"); err == nil {
+		t.Error("TakeRequest() by another client expected error")
+	}
+	// The rejected attempt leaves the request for its client
+	if _, err := store.TakeRequest(pushed.RequestURI, "client-a"); err != nil {
+		t.Errorf("TakeRequest() after another client's attempt unexpected error: %v", err)
+	}
+
+	expired, _ := store.Push("client-a", url.Values{}, false, -time.Second)
+	if _, err := store.TakeRequest(expired.RequestURI, "client-a"); err == nil {
+		t.Error("TakeRequest() of expired request expected error")
+	}
+}
+
+func TestRedeemCode(t *testing.T) {
+	store := NewStore()
+
+	value, err := store.IssueCode(Code{ClientID: "client-a", Subject: "alice"}, time.Minute)
+	if err != nil {
+		t.Fatalf("IssueCode() unexpected error: %v", err)
+	}
+
+	code, err := store.RedeemCode(value)
+	if err != nil {
+		t.Fatalf("RedeemCode() unexpected error: %v", err)
+	}
+	if code.ClientID != "client-a" || code.Subject != "alice" {
+		t.Errorf("RedeemCode() = %+v, want the issued code", code)
+	}
+
+	if _, err := store.RedeemCode(value); err == nil {
+		t.Error("RedeemCode() second use expected error")
+	}
+
+	expired, _ := store.IssueCode(Code{ClientID: "client-a"}, -time.Second)
+	if _, err := store.RedeemCode(expired); err == nil {
+		t.Error("RedeemCode() of expired code expected error")
+	}
+}
@@ -0,0 +1,187 @@
+package clients
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shogotsuneto/jwks-mock-api/pkg/config"
+)
+
+// ErrInvalidAccessToken is returned when a registration access token does not match the client
+var ErrInvalidAccessToken = errors.New("invalid registration access token")
+
+// Token endpoint authentication methods (RFC 7591 section 2, RFC 8705 section 2.1.1)
+const (
+	AuthMethodClientSecretBasic = "client_secret_basic"
+	AuthMethodClientSecretPost  = "client_secret_post"
+	AuthMethodPrivateKeyJWT     = "private_key_jwt"
+	AuthMethodTLSClientAuth     = "tls_client_auth"
+	AuthMethodNone              = "none"
+)
+
+// UsesClientSecret reports whether an auth method authenticates with a client secret.
+// Clients without a registered method are treated as secret-based.
+func UsesClientSecret(method string) bool {
+	return method == "" || method == AuthMethodClientSecretBasic || method == AuthMethodClientSecretPost
+}
+
+// Registration is a client held by the registry
+type Registration struct {
+	Client config.ClientConfig
+
+	// RegistrationAccessToken authorizes the management endpoints (RFC 7592).
+	// Clients loaded from config have none and cannot be managed.
+	RegistrationAccessToken string
+	IssuedAt                time.Time
+}
+
+// Registry keeps OAuth clients in memory, seeded from config and extended by dynamic registration
+type Registry struct {
+	clients map[string]*Registration
+	mu      sync.RWMutex // Protect concurrent access to clients
+}
+
+// NewRegistry creates a registry holding the configured clients
+func NewRegistry(seed []config.ClientConfig) *Registry {
+	r := &Registry{clients: make(map[string]*Registration)}
+	now := time.Now()
+	for _, client := range seed {
+		r.clients[client.ClientID] = &Registration{Client: client, IssuedAt: now}
+	}
+	return r
+}
+
+// randomValue returns a random URL-safe string
+func randomValue(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random value: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Get returns a copy of the client with the given ID
+func (r *Registry) Get(clientID string) (*config.ClientConfig, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	registration, ok := r.clients[clientID]
+	if !ok {
+		return nil, false
+	}
+	client := registration.Client
+	return &client, true
+}
+
+// Register adds a new client, assigning its client ID, a client secret when it
+// authenticates with one, and a registration access token
+func (r *Registry) Register(client config.ClientConfig) (*Registration, error) {
+	clientID, err := randomValue(16)
+	if err != nil {
+		return nil, err
+	}
+	accessToken, err := randomValue(32)
+	if err != nil {
+		return nil, err
+	}
+
+	client.ClientID = clientID
+	client.ClientSecret = ""
+	if err := assignSecret(&client); err != nil {
+		return nil, err
+	}
+
+	registration := &Registration{
+		Client:                  client,
+		RegistrationAccessToken: accessToken,
+		IssuedAt:                time.Now(),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.clients[clientID] = registration
+	copied := *registration
+	return &copied, nil
+}
+
+// Lookup returns a copy of a dynamically registered client after checking its registration access token
+func (r *Registry) Lookup(clientID, accessToken string) (*Registration, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	registration, err := r.authorized(clientID, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	copied := *registration
+	return &copied, nil
+}
+
+// Update replaces a dynamically registered client's metadata. The client keeps its ID and
+// secret, gets a secret if it switches to a secret-based auth method, and loses it otherwise.
+func (r *Registry) Update(clientID, accessToken string, client config.ClientConfig) (*Registration, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	registration, err := r.authorized(clientID, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	client.ClientID = clientID
+	client.ClientSecret = registration.Client.ClientSecret
+	if err := assignSecret(&client); err != nil {
+		return nil, err
+	}
+
+	registration.Client = client
+	copied := *registration
+	return &copied, nil
+}
+
+// Delete removes a dynamically registered client
+func (r *Registry) Delete(clientID, accessToken string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.authorized(clientID, accessToken); err != nil {
+		return err
+	}
+	delete(r.clients, clientID)
+	return nil
+}
+
+// authorized finds a client whose registration access token matches. Unknown clients report
+// the same error as a wrong token (RFC 7592 section 2). Callers must hold the lock.
+func (r *Registry) authorized(clientID, accessToken string) (*Registration, error) {
+	registration, ok := r.clients[clientID]
+	if !ok || registration.RegistrationAccessToken == "" || accessToken == "" ||
+		subtle.ConstantTimeCompare([]byte(accessToken), []byte(registration.RegistrationAccessToken)) != 1 {
+		return nil, ErrInvalidAccessToken
+	}
+	return registration, nil
+}
+
+// assignSecret gives clients using a secret-based auth method a secret and strips it from others
+func assignSecret(client *config.ClientConfig) error {
+	if !UsesClientSecret(client.TokenEndpointAuthMethod) {
+		client.ClientSecret = ""
+		return nil
+	}
+	if client.ClientSecret != "" {
+		return nil
+	}
+
+	secret, err := randomValue(32)
+	if err != nil {
+		return err
+	}
+	client.ClientSecret = secret
+	return nil
+}
@@ -0,0 +1,69 @@
+package clients
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shogotsuneto/jwks-mock-api/pkg/config"
+)
+
+func TestRegisterAndManage(t *testing.T) {
+	registry := NewRegistry(nil)
+
+	registration, err := registry.Register(config.ClientConfig{ClientID: "ignored", ClientName: "app"})
+	if err != nil {
+		t.Fatalf("Register() unexpected error: %v", err)
+	}
+	client := registration.Client
+	if client.ClientID == "" || client.ClientID == "ignored" {
+		t.Errorf("Register() client_id = %q, want a generated ID", client.ClientID)
+	}
+	if client.ClientSecret == "" || registration.RegistrationAccessToken == "" {
+		t.Errorf("Register() = %+v, want a client secret and registration access token", registration)
+	}
+
+	if _, err := registry.Lookup(client.ClientID, "wrong"); !errors.Is(err, ErrInvalidAccessToken) {
+		t.Errorf("Lookup() with wrong token error = %v, want ErrInvalidAccessToken", err)
+	}
+
+	// Updates keep the secret
+	updated, err := registry.Update(client.ClientID, registration.RegistrationAccessToken, config.ClientConfig{ClientName: "renamed"})
+	if err != nil {
+		t.Fatalf("Update() unexpected error: %v", err)
+	}
+	if updated.Client.ClientName != "renamed" || updated.Client.ClientSecret != client.ClientSecret {
+		t.Errorf("Update() = %+v, want renamed client with the same secret", updated.Client)
+	}
+
+	// Becoming a public client drops the secret
+	updated, err = registry.Update(client.ClientID, registration.RegistrationAccessToken, config.ClientConfig{TokenEndpointAuthMethod: AuthMethodNone})
+	if err != nil {
+		t.Fatalf("Update() unexpected error: %v", err)
+	}
+	if updated.Client.ClientSecret != "" {
+		t.Errorf("Update() to public client kept secret %q", updated.Client.ClientSecret)
+	}
+
+	if err := registry.Delete(client.ClientID, registration.RegistrationAccessToken); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	if _, ok := registry.Get(client.ClientID); ok {
+		t.Error("Get() after Delete() found the client")
+	}
+}
+
+func TestConfiguredClientsCannotBeManaged(t *testing.T) {
+	registry := NewRegistry([]config.ClientConfig{{ClientID: "static", ClientSecret: "secret"}})
+
+	client, ok := registry.Get("static")
+	if !ok || client.ClientSecret != "secret" {
+		t.Fatalf("Get() = %+v, %v, want the configured client", client, ok)
+	}
+
+	if _, err := registry.Lookup("static", ""); !errors.Is(err, ErrInvalidAccessToken) {
+		t.Errorf("Lookup() of configured client error = %v, want ErrInvalidAccessToken", err)
+	}
+	if err := registry.Delete("static", ""); !errors.Is(err, ErrInvalidAccessToken) {
+		t.Errorf("Delete() of configured client error = %v, want ErrInvalidAccessToken", err)
+	}
+}
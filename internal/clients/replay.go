@@ -0,0 +1,36 @@
+package clients
+
+import (
+	"sync"
+	"time"
+)
+
+// ReplayCache remembers single-use identifiers such as assertion jti values until they expire
+type ReplayCache struct {
+	seen map[string]time.Time
+	mu   sync.Mutex // Protect concurrent access to seen
+}
+
+// NewReplayCache creates a new replay cache
+func NewReplayCache() *ReplayCache {
+	return &ReplayCache{seen: make(map[string]time.Time)}
+}
+
+// Use records an identifier until expiresAt and reports false when it was already used
+func (c *ReplayCache) Use(id string, expiresAt time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for key, expiry := range c.seen {
+		if now.After(expiry) {
+			delete(c.seen, key)
+		}
+	}
+
+	if _, used := c.seen[id]; used {
+		return false
+	}
+	c.seen[id] = expiresAt
+	return true
+}
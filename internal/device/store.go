@@ -0,0 +1,248 @@
+package device
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Status is the state of a device authorization request
+type Status int
+
+const (
+	StatusPending Status = iota
+	StatusApproved
+	StatusDenied
+)
+
+// PollResult is the outcome of a token request polling a device code
+type PollResult int
+
+const (
+	PollPending PollResult = iota
+	PollSlowDown
+	PollApproved
+	PollDenied
+	PollExpired
+	PollInvalid
+)
+
+// slowDownIncrement is added to the polling interval on every slow_down response (RFC 8628 section 3.5)
+const slowDownIncrement = 5 * time.Second
+
+// userCodeCharset avoids vowels and ambiguous characters (RFC 8628 section 6.1)
+const userCodeCharset = "BCDFGHJKLMNPQRSTVWXZ"
+
+// Authorization represents a pending device authorization request
+type Authorization struct {
+	DeviceCode string
+	UserCode   string
+	ClientID   string
+	Scope      string
+	Subject    string
+	Status     Status
+	ExpiresAt  time.Time
+	Interval   time.Duration
+	lastPoll   time.Time
+}
+
+// Store keeps device authorization requests in memory
+type Store struct {
+	byDeviceCode map[string]*Authorization
+	byUserCode   map[string]*Authorization
+	mu           sync.Mutex // Protect concurrent access to authorizations
+}
+
+// NewStore creates a new device authorization store
+func NewStore() *Store {
+	return &Store{
+		byDeviceCode: make(map[string]*Authorization),
+		byUserCode:   make(map[string]*Authorization),
+	}
+}
+
+// NormalizeUserCode uppercases a user code and strips separators so codes can be typed loosely
+func NormalizeUserCode(userCode string) string {
+	userCode = strings.ToUpper(userCode)
+	userCode = strings.ReplaceAll(userCode, "-", "")
+	return strings.ReplaceAll(userCode, " ", "")
+}
+
+// generateUserCode creates a user code in the form XXXX-XXXX
+func generateUserCode() (string, error) {
+	code := make([]byte, 8)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(userCodeCharset))))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate user code: %w", err)
+		}
+		code[i] = userCodeCharset[n.Int64()]
+	}
+	return string(code[:4]) + "-" + string(code[4:]), nil
+}
+
+// generateDeviceCode creates an opaque, high-entropy device code
+func generateDeviceCode() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate device code: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Create starts a new device authorization request
+func (s *Store) Create(clientID, scope string, expiresIn, interval time.Duration) (*Authorization, error) {
+	deviceCode, err := generateDeviceCode()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeExpired()
+
+	// Retry on the unlikely event of a user code collision
+	var userCode string
+	for {
+		userCode, err = generateUserCode()
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := s.byUserCode[NormalizeUserCode(userCode)]; !exists {
+			break
+		}
+	}
+
+	auth := &Authorization{
+		DeviceCode: deviceCode,
+		UserCode:   userCode,
+		ClientID:   clientID,
+		Scope:      scope,
+		Status:     StatusPending,
+		ExpiresAt:  time.Now().Add(expiresIn),
+		Interval:   interval,
+	}
+
+	s.byDeviceCode[deviceCode] = auth
+	s.byUserCode[NormalizeUserCode(userCode)] = auth
+
+	copied := *auth
+	return &copied, nil
+}
+
+// Lookup returns a copy of the pending, unexpired authorization for a user code
+func (s *Store) Lookup(userCode string) (*Authorization, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	auth, err := s.pendingByUserCode(userCode)
+	if err != nil {
+		return nil, err
+	}
+
+	copied := *auth
+	return &copied, nil
+}
+
+// Approve grants the authorization identified by a user code to the given subject
+func (s *Store) Approve(userCode, subject string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	auth, err := s.pendingByUserCode(userCode)
+	if err != nil {
+		return err
+	}
+
+	auth.Status = StatusApproved
+	auth.Subject = subject
+	return nil
+}
+
+// Deny rejects the authorization identified by a user code
+func (s *Store) Deny(userCode string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	auth, err := s.pendingByUserCode(userCode)
+	if err != nil {
+		return err
+	}
+
+	auth.Status = StatusDenied
+	return nil
+}
+
+// pendingByUserCode finds a pending, unexpired authorization. Callers must hold the lock.
+func (s *Store) pendingByUserCode(userCode string) (*Authorization, error) {
+	auth, ok := s.byUserCode[NormalizeUserCode(userCode)]
+	if !ok {
+		return nil, fmt.Errorf("unknown user code")
+	}
+	if time.Now().After(auth.ExpiresAt) {
+		return nil, fmt.Errorf("user code has expired")
+	}
+	if auth.Status != StatusPending {
+		return nil, fmt.Errorf("user code has already been used")
+	}
+	return auth, nil
+}
+
+// Poll checks the state of a device code on behalf of a token request from clientID.
+// Approved and denied authorizations are consumed by the poll that reports them.
+func (s *Store) Poll(deviceCode, clientID string) (PollResult, *Authorization) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	auth, ok := s.byDeviceCode[deviceCode]
+	if !ok || auth.ClientID != clientID {
+		return PollInvalid, nil
+	}
+
+	now := time.Now()
+	if now.After(auth.ExpiresAt) {
+		return PollExpired, nil
+	}
+
+	copied := *auth
+	switch auth.Status {
+	case StatusApproved:
+		s.remove(auth)
+		return PollApproved, &copied
+	case StatusDenied:
+		s.remove(auth)
+		return PollDenied, &copied
+	}
+
+	// Polling faster than the interval slows the client down for subsequent requests
+	tooFast := !auth.lastPoll.IsZero() && now.Sub(auth.lastPoll) < auth.Interval
+	auth.lastPoll = now
+	if tooFast {
+		auth.Interval += slowDownIncrement
+		return PollSlowDown, &copied
+	}
+
+	return PollPending, &copied
+}
+
+// remove deletes an authorization from both indexes. Callers must hold the lock.
+func (s *Store) remove(auth *Authorization) {
+	delete(s.byDeviceCode, auth.DeviceCode)
+	delete(s.byUserCode, NormalizeUserCode(auth.UserCode))
+}
+
+// removeExpired drops authorizations that expired long enough ago that no client
+// should still be polling for them. Callers must hold the lock.
+func (s *Store) removeExpired() {
+	cutoff := time.Now().Add(-time.Hour)
+	for _, auth := range s.byDeviceCode {
+		if auth.ExpiresAt.Before(cutoff) {
+			s.remove(auth)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package device
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPollLifecycle(t *testing.T) {
+	store := NewStore()
+
+	auth, err := store.Create("cli", "openid", time.Minute, 0)
+	if err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	if result, _ := store.Poll(auth.DeviceCode, "other-client"); result != PollInvalid {
+		t.Errorf("Poll() with wrong client = %v, want PollInvalid", result)
+	}
+	if result, _ := store.Poll(auth.DeviceCode, "cli"); result != PollPending {
+		t.Errorf("Poll() before approval = %v, want PollPending", result)
+	}
+
+	// User codes are matched loosely
+	if err := store.Approve(" "+NormalizeUserCode(auth.UserCode)+" ", "alice"); err != nil {
+		t.Fatalf("Approve() unexpected error: %v", err)
+	}
+	if err := store.Deny(auth.UserCode); err == nil {
+		t.Error("Deny() after approval expected error")
+	}
+
+	result, approved := store.Poll(auth.DeviceCode, "cli")
+	if result != PollApproved || approved.Subject != "alice" {
+		t.Errorf("Poll() after approval = %v (%v), want PollApproved for alice", result, approved)
+	}
+
+	// The device code is single use
+	if result, _ := store.Poll(auth.DeviceCode, "cli"); result != PollInvalid {
+		t.Errorf("Poll() after redemption = %v, want PollInvalid", result)
+	}
+}
+
+func TestPollSlowDown(t *testing.T) {
+	store := NewStore()
+
+	auth, err := store.Create("cli", "", time.Minute, time.Hour)
+	if err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	if result, _ := store.Poll(auth.DeviceCode, "cli"); result != PollPending {
+		t.Errorf("first Poll() = %v, want PollPending", result)
+	}
+	result, polled := store.Poll(auth.DeviceCode, "cli")
+	if result != PollSlowDown {
+		t.Errorf("second Poll() = %v, want PollSlowDown", result)
+	}
+	if polled.Interval != time.Hour {
+		t.Errorf("Poll() returned interval %v, want the interval before the increase", polled.Interval)
+	}
+}
+
+func TestPollExpiredAndDenied(t *testing.T) {
+	store := NewStore()
+
+	expired, err := store.Create("cli", "", -time.Second, 0)
+	if err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	if result, _ := store.Poll(expired.DeviceCode, "cli"); result != PollExpired {
+		t.Errorf("Poll() on expired code = %v, want PollExpired", result)
+	}
+	if err := store.Approve(expired.UserCode, "alice"); err == nil {
+		t.Error("Approve() on expired code expected error")
+	}
+
+	denied, err := store.Create("cli", "", time.Minute, 0)
+	if err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	if err := store.Deny(denied.UserCode); err != nil {
+		t.Fatalf("Deny() unexpected error: %v", err)
+	}
+	if result, _ := store.Poll(denied.DeviceCode, "cli"); result != PollDenied {
+		t.Errorf("Poll() on denied code = %v, want PollDenied", result)
+	}
+}
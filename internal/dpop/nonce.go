@@ -0,0 +1,60 @@
+package dpop
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// NonceStore issues server-provided DPoP nonces (RFC 9449 section 8) and accepts them until they expire
+type NonceStore struct {
+	nonces   map[string]time.Time
+	lifetime time.Duration
+	mu       sync.Mutex // Protect concurrent access to nonces
+}
+
+// NewNonceStore creates a nonce store whose nonces are valid for the given lifetime
+func NewNonceStore(lifetime time.Duration) *NonceStore {
+	return &NonceStore{
+		nonces:   make(map[string]time.Time),
+		lifetime: lifetime,
+	}
+}
+
+// Issue creates a new nonce
+func (s *NonceStore) Issue() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	nonce := base64.RawURLEncoding.EncodeToString(b)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeExpired()
+	s.nonces[nonce] = time.Now().Add(s.lifetime)
+	return nonce, nil
+}
+
+// Valid reports whether a nonce was issued by the store and has not expired.
+// Nonces may be used by several proofs during their lifetime.
+func (s *NonceStore) Valid(nonce string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expiresAt, ok := s.nonces[nonce]
+	return ok && time.Now().Before(expiresAt)
+}
+
+// removeExpired drops expired nonces. Callers must hold the lock.
+func (s *NonceStore) removeExpired() {
+	now := time.Now()
+	for nonce, expiresAt := range s.nonces {
+		if now.After(expiresAt) {
+			delete(s.nonces, nonce)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package faults
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+)
+
+// Fault types
+const (
+	TypeLatency     = "latency"      // delay the response, then serve it normally
+	TypeStatus      = "status"       // answer with an error status such as 503 or 429
+	TypeReset       = "reset"        // reset the connection without a response
+	TypeTruncate    = "truncate"     // cut the response body in half
+	TypeMalformed   = "malformed"    // corrupt the response body so it is not valid JSON
+	TypeEmptyKeys   = "empty_keys"   // answer with an empty JSON Web Key Set
+	TypeContentType = "content_type" // serve the response with a wrong Content-Type
+)
+
+// types lists the supported fault types
+var types = []string{TypeLatency, TypeStatus, TypeReset, TypeTruncate, TypeMalformed, TypeEmptyKeys, TypeContentType}
+
+// Fault describes a failure injected into matching requests
+type Fault struct {
+	ID          string  `json:"id"`
+	Path        string  `json:"path"`                   // exact path, or a prefix ending in *
+	Method      string  `json:"method,omitempty"`       // any method when empty
+	Type        string  `json:"type"`                   // one of the Type constants
+	Status      int     `json:"status,omitempty"`       // status of TypeStatus faults
+	ContentType string  `json:"content_type,omitempty"` // Content-Type of TypeContentType faults, text/html by default
+	DelayMs     int     `json:"delay_ms,omitempty"`     // latency added before any fault type
+	Probability float64 `json:"probability,omitempty"`  // chance of applying to a matching request, always when 0
+	Count       int     `json:"count,omitempty"`        // remaining requests to fault, unlimited when 0
+	Applied     int     `json:"applied"`                // requests the fault was applied to
+}
+
+// Validate checks the fault settings and fills in defaults
+func (f *Fault) Validate() error {
+	if f.Path == "" || !strings.HasPrefix(f.Path, "/") {
+		return fmt.Errorf("path must start with /")
+	}
+	f.Method = strings.ToUpper(f.Method)
+
+	valid := false
+	for _, t := range types {
+		if f.Type == t {
+			valid = true
+		}
+	}
+	if !valid {
+		return fmt.Errorf("unsupported fault type %q, expected one of %s", f.Type, strings.Join(types, ", "))
+	}
+
+	switch {
+	case f.Type == TypeStatus && (f.Status < 400 || f.Status > 599):
+		return fmt.Errorf("status faults require a status between 400 and 599")
+	case f.Type == TypeLatency && f.DelayMs <= 0:
+		return fmt.Errorf("latency faults require a positive delay_ms")
+	case f.DelayMs < 0:
+		return fmt.Errorf("delay_ms must not be negative")
+	case f.Probability < 0 || f.Probability > 1:
+		return fmt.Errorf("probability must be between 0 and 1")
+	case f.Count < 0:
+		return fmt.Errorf("count must not be negative")
+	}
+
+	if f.Type == TypeContentType && f.ContentType == "" {
+		f.ContentType = "text/html"
+	}
+	return nil
+}
+
+// matches reports whether the fault targets the request method and path
+func (f *Fault) matches(method, path string) bool {
+	if f.Method != "" && f.Method != method {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(f.Path, "*"); ok {
+		return strings.HasPrefix(path, prefix)
+	}
+	return f.Path == path
+}
+
+// Store holds the active faults in the order they were added
+type Store struct {
+	faults []*Fault
+	nextID int
+	mu     sync.Mutex // Protect concurrent access to faults
+}
+
+// NewStore creates a new fault store
+func NewStore() *Store {
+	return &Store{}
+}
+
+// Add validates a fault, assigns it an ID and activates it
+func (s *Store) Add(fault Fault) (Fault, error) {
+	if err := fault.Validate(); err != nil {
+		return Fault{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	fault.ID = fmt.Sprintf("fault-%d", s.nextID)
+	fault.Applied = 0
+	s.faults = append(s.faults, &fault)
+	return fault, nil
+}
+
+// Match returns the first fault to apply to a request, if any. Faults limited to a
+// count are removed once they have been applied that many times.
+func (s *Store) Match(method, path string) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, fault := range s.faults {
+		if !fault.matches(method, path) {
+			continue
+		}
+		if fault.Probability > 0 && rand.Float64() >= fault.Probability {
+			continue
+		}
+
+		fault.Applied++
+		applied := *fault
+		if fault.Count > 0 {
+			fault.Count--
+			if fault.Count == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return &applied
+	}
+	return nil
+}
+
+// List returns the active faults
+func (s *Store) List() []Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]Fault, 0, len(s.faults))
+	for _, fault := range s.faults {
+		list = append(list, *fault)
+	}
+	return list
+}
+
+// Remove deactivates a fault and reports whether it existed
+func (s *Store) Remove(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, fault := range s.faults {
+		if fault.ID == id {
+			s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Clear removes all faults and returns how many were removed
+func (s *Store) Clear() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := len(s.faults)
+	s.faults = nil
+	return count
+}
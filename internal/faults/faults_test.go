@@ -0,0 +1,76 @@
+package faults
+
+import "testing"
+
+func TestFaultValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		fault   Fault
+		wantErr bool
+	}{
+		{"status", Fault{Path: "/.well-known/jwks.json", Type: TypeStatus, Status: 503}, false},
+		{"rate limited", Fault{Path: "/introspect", Type: TypeStatus, Status: 429}, false},
+		{"status out of range", Fault{Path: "/introspect", Type: TypeStatus, Status: 200}, true},
+		{"latency", Fault{Path: "/*", Type: TypeLatency, DelayMs: 100}, false},
+		{"latency without delay", Fault{Path: "/*", Type: TypeLatency}, true},
+		{"relative path", Fault{Path: "introspect", Type: TypeReset}, true},
+		{"unknown type", Fault{Path: "/introspect", Type: "explode"}, true},
+		{"probability above one", Fault{Path: "/introspect", Type: TypeReset, Probability: 1.5}, true},
+		{"negative count", Fault{Path: "/introspect", Type: TypeReset, Count: -1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.fault.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStoreMatch(t *testing.T) {
+	store := NewStore()
+	limited, err := store.Add(Fault{Path: "/.well-known/jwks.json", Type: TypeStatus, Status: 503, Count: 2})
+	if err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+	if _, err := store.Add(Fault{Path: "/tenants/*", Method: "post", Type: TypeReset}); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+	if _, err := store.Add(Fault{Path: "/introspect", Type: TypeReset, Probability: 0.000001}); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+
+	// A count-limited fault applies the given number of times, then is removed
+	for i := 0; i < 2; i++ {
+		if fault := store.Match("GET", "/.well-known/jwks.json"); fault == nil || fault.ID != limited.ID {
+			t.Fatalf("Match() request %d = %v, want %s", i+1, fault, limited.ID)
+		}
+	}
+	if fault := store.Match("GET", "/.well-known/jwks.json"); fault != nil {
+		t.Errorf("Match() after count exhausted = %s, want nil", fault.ID)
+	}
+
+	tests := []struct {
+		method    string
+		path      string
+		wantFault bool
+	}{
+		{"POST", "/tenants/a/token", true},
+		{"GET", "/tenants/a/.well-known/jwks.json", false},
+		{"POST", "/token", false},
+		{"POST", "/introspect", false}, // applies with a negligible probability
+	}
+	for _, tt := range tests {
+		if fault := store.Match(tt.method, tt.path); (fault != nil) != tt.wantFault {
+			t.Errorf("Match(%s, %s) = %v, wantFault %v", tt.method, tt.path, fault, tt.wantFault)
+		}
+	}
+
+	if len(store.List()) != 2 {
+		t.Errorf("List() = %d faults, want 2", len(store.List()))
+	}
+	if cleared := store.Clear(); cleared != 2 {
+		t.Errorf("Clear() = %d, want 2", cleared)
+	}
+}
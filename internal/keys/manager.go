@@ -4,10 +4,12 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
+	"encoding/json"
 	"encoding/pem"
 	"fmt"
 	"math/big"
 	"sync"
+	"time"
 
 	"github.com/lestrrat-go/jwx/v2/jwk"
 )
@@ -15,6 +17,7 @@ import (
 // KeyPair represents an RSA key pair with metadata
 type KeyPair struct {
 	Kid        string `json:"kid"`
+	Alg        string `json:"alg"`
 	PrivateKey *rsa.PrivateKey
 	PublicKey  *rsa.PublicKey
 	JWK        jwk.Key
@@ -24,15 +27,31 @@ type KeyPair struct {
 type Manager struct {
 	keys []KeyPair
 	mu   sync.RWMutex // Protect concurrent access to keys slice
+
+	// When the key set last changed, and its encoded JWKS until the next change
+	modifiedAt time.Time
+	jwksJSON   []byte
 }
 
 // NewManager creates a new key manager
 func NewManager() *Manager {
-	return &Manager{
+	m := &Manager{
 		keys: make([]KeyPair, 0),
 	}
+	m.touch()
+	return m
+}
+
+// touch records a key set change. Modification times have the one-second resolution of
+// HTTP dates, so changes within the same second are only told apart by the JWKS ETag.
+func (m *Manager) touch() {
+	m.modifiedAt = time.Now().UTC().Truncate(time.Second)
+	m.jwksJSON = nil
 }
 
+// DefaultAlgorithm is the JWS algorithm used by generated key pairs
+const DefaultAlgorithm = "RS256"
+
 // generateKeyPair creates a new RSA key pair with the specified key ID
 func (m *Manager) generateKeyPair(kid string) (KeyPair, error) {
 	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
@@ -51,7 +70,7 @@ func (m *Manager) generateKeyPair(kid string) (KeyPair, error) {
 		return KeyPair{}, fmt.Errorf("failed to set key ID for %s: %w", kid, err)
 	}
 
-	if err := jwkKey.Set(jwk.AlgorithmKey, "RS256"); err != nil {
+	if err := jwkKey.Set(jwk.AlgorithmKey, DefaultAlgorithm); err != nil {
 		return KeyPair{}, fmt.Errorf("failed to set algorithm for %s: %w", kid, err)
 	}
 
@@ -61,6 +80,7 @@ func (m *Manager) generateKeyPair(kid string) (KeyPair, error) {
 
 	return KeyPair{
 		Kid:        kid,
+		Alg:        DefaultAlgorithm,
 		PrivateKey: privateKey,
 		PublicKey:  &privateKey.PublicKey,
 		JWK:        jwkKey,
@@ -82,6 +102,7 @@ func (m *Manager) GenerateKeys(keyIDs []string) error {
 		m.keys = append(m.keys, keyPair)
 	}
 
+	m.touch()
 	return nil
 }
 
@@ -122,6 +143,31 @@ func (m *Manager) GetJWKS() (jwk.Set, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	return m.publicKeySet()
+}
+
+// JWKSDocument returns the encoded JSON Web Key Set and when the key set last changed.
+// The encoding is kept until the keys change, so unchanged key sets serve identical bytes.
+func (m *Manager) JWKSDocument() ([]byte, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.jwksJSON == nil {
+		set, err := m.publicKeySet()
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		data, err := json.Marshal(set)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("failed to encode JWKS: %w", err)
+		}
+		m.jwksJSON = data
+	}
+	return m.jwksJSON, m.modifiedAt, nil
+}
+
+// publicKeySet builds the JSON Web Key Set; callers must hold the lock
+func (m *Manager) publicKeySet() (jwk.Set, error) {
 	set := jwk.NewSet()
 
 	for _, keyPair := range m.keys {
@@ -178,6 +224,7 @@ func (m *Manager) AddKey(kid string) error {
 	}
 
 	m.keys = append(m.keys, keyPair)
+	m.touch()
 	return nil
 }
 
@@ -196,6 +243,7 @@ func (m *Manager) RemoveKey(kid string) error {
 		if key.Kid == kid {
 			// Remove key from slice
 			m.keys = append(m.keys[:i], m.keys[i+1:]...)
+			m.touch()
 			return nil
 		}
 	}
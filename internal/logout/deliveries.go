@@ -0,0 +1,62 @@
+package logout
+
+import (
+	"sync"
+	"time"
+)
+
+// Logout channels (OpenID Connect Back-Channel and Front-Channel Logout 1.0)
+const (
+	ChannelBackChannel  = "back_channel"
+	ChannelFrontChannel = "front_channel"
+)
+
+// Delivery records a logout notification sent, or rendered, for a client
+type Delivery struct {
+	ClientID    string    `json:"client_id"`
+	Channel     string    `json:"channel"`
+	URI         string    `json:"uri"`
+	Subject     string    `json:"sub,omitempty"`
+	SessionID   string    `json:"sid,omitempty"`
+	LogoutToken string    `json:"logout_token,omitempty"` // back-channel only
+	StatusCode  int       `json:"status_code,omitempty"`  // response status of the back-channel POST
+	Error       string    `json:"error,omitempty"`
+	DeliveredAt time.Time `json:"delivered_at"`
+}
+
+// DeliveryLog keeps the logout notifications in delivery order
+type DeliveryLog struct {
+	deliveries []Delivery
+	mu         sync.RWMutex // Protect concurrent access to deliveries
+}
+
+// NewDeliveryLog creates a new delivery log
+func NewDeliveryLog() *DeliveryLog {
+	return &DeliveryLog{}
+}
+
+// Record appends a delivery to the log
+func (l *DeliveryLog) Record(delivery Delivery) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.deliveries = append(l.deliveries, delivery)
+}
+
+// List returns all deliveries in delivery order
+func (l *DeliveryLog) List() []Delivery {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return append([]Delivery{}, l.deliveries...)
+}
+
+// Clear removes all deliveries and returns how many were removed
+func (l *DeliveryLog) Clear() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	count := len(l.deliveries)
+	l.deliveries = nil
+	return count
+}
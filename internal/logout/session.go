@@ -0,0 +1,84 @@
+package logout
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Session is the provider session of a subject, shared by every client it signed in to
+type Session struct {
+	ID      string
+	Subject string
+	Clients []string // client IDs in sign-in order
+}
+
+// SessionStore tracks one session per subject so logout can notify every client of it
+type SessionStore struct {
+	sessions map[string]*Session // by subject
+	mu       sync.Mutex          // Protect concurrent access to sessions
+}
+
+// NewSessionStore creates a new session store
+func NewSessionStore() *SessionStore {
+	return &SessionStore{
+		sessions: make(map[string]*Session),
+	}
+}
+
+// Join records that a client signed in the subject and returns the session ID (sid),
+// starting a session when the subject has none
+func (s *SessionStore) Join(subject, clientID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[subject]
+	if !ok {
+		id, err := randomID()
+		if err != nil {
+			return "", err
+		}
+		session = &Session{ID: id, Subject: subject}
+		s.sessions[subject] = session
+	}
+
+	for _, existing := range session.Clients {
+		if existing == clientID {
+			return session.ID, nil
+		}
+	}
+	session.Clients = append(session.Clients, clientID)
+	return session.ID, nil
+}
+
+// End removes and returns the session with the given sid, or the subject's session when
+// sid is empty. With a clientID, only a session that client signed in to is ended. It
+// returns nil when no session matches.
+func (s *SessionStore) End(sid, subject, clientID string) *Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, session := range s.sessions {
+		if clientID != "" && !slices.Contains(session.Clients, clientID) {
+			continue
+		}
+		if (sid != "" && session.ID == sid) || (sid == "" && subject != "" && session.Subject == subject) {
+			delete(s.sessions, key)
+			copied := *session
+			copied.Clients = append([]string(nil), session.Clients...)
+			return &copied
+		}
+	}
+	return nil
+}
+
+// randomID returns a random URL-safe session ID
+func randomID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate session ID: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,60 @@
+package logout
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSessionJoinAndEnd(t *testing.T) {
+	store := NewSessionStore()
+
+	sid, err := store.Join("alice", "client-a")
+	if err != nil {
+		t.Fatalf("Join() unexpected error: %v", err)
+	}
+	again, _ := store.Join("alice", "client-b")
+	store.Join("alice", "client-a")
+	if again != sid {
+		t.Errorf("Join() sid = %s, want the existing session %s", again, sid)
+	}
+	other, _ := store.Join("bob", "client-a")
+	if other == sid {
+		t.Error("Join() gave two subjects the same session")
+	}
+
+	session := store.End(sid, "", "")
+	if session == nil {
+		t.Fatal("End() by sid returned no session")
+	}
+	if session.Subject != "alice" || !reflect.DeepEqual(session.Clients, []string{"client-a", "client-b"}) {
+		t.Errorf("End() = %+v, want alice with client-a and client-b", session)
+	}
+	if store.End(sid, "", "") != nil {
+		t.Error("End() of an ended session expected nil")
+	}
+
+	// A client only ends sessions it signed in to
+	if session := store.End("", "bob", "client-b"); session != nil {
+		t.Errorf("End() by subject for another client = %+v, want nil", session)
+	}
+	if session := store.End("", "bob", "client-a"); session == nil || session.ID != other {
+		t.Errorf("End() by subject = %+v, want bob's session", session)
+	}
+}
+
+func TestDeliveryLog(t *testing.T) {
+	log := NewDeliveryLog()
+	log.Record(Delivery{ClientID: "client-a", Channel: ChannelBackChannel})
+	log.Record(Delivery{ClientID: "client-b", Channel: ChannelFrontChannel})
+
+	deliveries := log.List()
+	if len(deliveries) != 2 || deliveries[0].ClientID != "client-a" {
+		t.Errorf("List() = %+v, want both deliveries in order", deliveries)
+	}
+	if cleared := log.Clear(); cleared != 2 {
+		t.Errorf("Clear() = %d, want 2", cleared)
+	}
+	if len(log.List()) != 0 {
+		t.Error("List() after Clear() expected no deliveries")
+	}
+}
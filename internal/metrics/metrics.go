@@ -0,0 +1,223 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ContentType is the Content-Type of the Prometheus text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Key rotation events
+const (
+	EventKeyAdded   = "added"
+	EventKeyRemoved = "removed"
+)
+
+// durationBuckets are the upper bounds of the request latency histogram in seconds
+var durationBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// counterVec is a counter partitioned by label values
+type counterVec struct {
+	name       string
+	help       string
+	labelNames []string
+	values     map[string]float64
+}
+
+// histogram holds the observations of one label combination
+type histogram struct {
+	buckets []uint64 // cumulative counts per bucket in durationBuckets
+	sum     float64
+	count   uint64
+}
+
+// histogramVec is a histogram partitioned by label values
+type histogramVec struct {
+	name       string
+	help       string
+	labelNames []string
+	series     map[string]*histogram
+}
+
+// Registry collects the service metrics and renders them in the Prometheus text format.
+// One registry is shared by the top-level issuer and every tenant.
+type Registry struct {
+	requests       *counterVec
+	durations      *histogramVec
+	tokens         *counterVec
+	introspections *counterVec
+	keyRotations   *counterVec
+	keyCounts      map[string]func() int // key count per issuer, sampled on scrape
+	mu             sync.Mutex            // Protect concurrent access to the metric values
+}
+
+// NewRegistry creates a registry with all metrics at zero
+func NewRegistry() *Registry {
+	return &Registry{
+		requests: newCounterVec("jwks_mock_http_requests_total",
+			"HTTP requests by method, route and status.", "method", "route", "status"),
+		durations: &histogramVec{
+			name:       "jwks_mock_http_request_duration_seconds",
+			help:       "HTTP request latency by method, route and status.",
+			labelNames: []string{"method", "route", "status"},
+			series:     make(map[string]*histogram),
+		},
+		tokens: newCounterVec("jwks_mock_tokens_issued_total",
+			"Tokens signed by key ID and algorithm.", "kid", "alg"),
+		introspections: newCounterVec("jwks_mock_introspections_total",
+			"Token introspection results, with the reason inactive tokens were rejected.", "active", "reason"),
+		keyRotations: newCounterVec("jwks_mock_key_rotations_total",
+			"Keys added or removed at runtime by issuer.", "issuer", "event"),
+		keyCounts: make(map[string]func() int),
+	}
+}
+
+func newCounterVec(name, help string, labelNames ...string) *counterVec {
+	return &counterVec{name: name, help: help, labelNames: labelNames, values: make(map[string]float64)}
+}
+
+// labelKey joins label values into a map key; label values cannot contain the separator
+func labelKey(values ...string) string {
+	return strings.Join(values, "\xff")
+}
+
+// ObserveRequest records a served request and its latency
+func (r *Registry) ObserveRequest(method, route string, status int, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := labelKey(method, route, strconv.Itoa(status))
+	r.requests.values[key]++
+
+	h, ok := r.durations.series[key]
+	if !ok {
+		h = &histogram{buckets: make([]uint64, len(durationBuckets))}
+		r.durations.series[key] = h
+	}
+	seconds := duration.Seconds()
+	for i, bound := range durationBuckets {
+		if seconds <= bound {
+			h.buckets[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// TokenIssued records a token signed with the given key
+func (r *Registry) TokenIssued(kid, alg string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens.values[labelKey(kid, alg)]++
+}
+
+// Introspected records an introspection result; reason is empty for active tokens
+func (r *Registry) Introspected(active bool, reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.introspections.values[labelKey(strconv.FormatBool(active), reason)]++
+}
+
+// KeyRotated records a key added or removed for an issuer
+func (r *Registry) KeyRotated(issuer, event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.keyRotations.values[labelKey(issuer, event)]++
+}
+
+// TrackKeys reports the key count of an issuer in the keys gauge
+func (r *Registry) TrackKeys(issuer string, count func() int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.keyCounts[issuer] = count
+}
+
+// UntrackKeys stops reporting the key count of an issuer
+func (r *Registry) UntrackKeys(issuer string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.keyCounts, issuer)
+}
+
+// Write renders all metrics in the Prometheus text exposition format
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var b strings.Builder
+	r.requests.write(&b)
+	r.durations.write(&b)
+	r.tokens.write(&b)
+	r.introspections.write(&b)
+
+	fmt.Fprintf(&b, "# HELP jwks_mock_keys Signing keys currently published by issuer.\n# TYPE jwks_mock_keys gauge\n")
+	for _, issuer := range sortedKeys(r.keyCounts) {
+		fmt.Fprintf(&b, "jwks_mock_keys%s %d\n", formatLabels([]string{"issuer"}, []string{issuer}), r.keyCounts[issuer]())
+	}
+
+	r.keyRotations.write(&b)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (c *counterVec) write(b *strings.Builder) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	for _, key := range sortedKeys(c.values) {
+		fmt.Fprintf(b, "%s%s %s\n", c.name, formatLabels(c.labelNames, strings.Split(key, "\xff")), formatValue(c.values[key]))
+	}
+}
+
+func (h *histogramVec) write(b *strings.Builder) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	bucketLabels := append(append([]string{}, h.labelNames...), "le")
+	for _, key := range sortedKeys(h.series) {
+		values := strings.Split(key, "\xff")
+		series := h.series[key]
+		for i, bound := range durationBuckets {
+			fmt.Fprintf(b, "%s_bucket%s %d\n", h.name, formatLabels(bucketLabels, append(values, formatValue(bound))), series.buckets[i])
+		}
+		fmt.Fprintf(b, "%s_bucket%s %d\n", h.name, formatLabels(bucketLabels, append(values, "+Inf")), series.count)
+		fmt.Fprintf(b, "%s_sum%s %s\n", h.name, formatLabels(h.labelNames, values), formatValue(series.sum))
+		fmt.Fprintf(b, "%s_count%s %d\n", h.name, formatLabels(h.labelNames, values), series.count)
+	}
+}
+
+// formatLabels renders a label set such as {method="GET",route="/token"}
+func formatLabels(names, values []string) string {
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + `="` + escapeLabelValue(values[i]) + `"`
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// escapeLabelValue escapes backslashes, double quotes and newlines in a label value
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// sortedKeys returns the keys of a map in order, so the output is stable between scrapes
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegistryWrite(t *testing.T) {
+	registry := NewRegistry()
+	registry.ObserveRequest("GET", "/.well-known/jwks.json", 200, 3*time.Millisecond)
+	registry.ObserveRequest("GET", "/.well-known/jwks.json", 200, 2*time.Second)
+	registry.TokenIssued("key-1", "RS256")
+	registry.Introspected(false, "expired")
+	registry.KeyRotated("http://localhost:3000", EventKeyAdded)
+	registry.TrackKeys("http://localhost:3000", func() int { return 3 })
+	registry.TrackKeys(`http://"quoted"`, func() int { return 1 })
+
+	var b strings.Builder
+	if err := registry.Write(&b); err != nil {
+		t.Fatalf("Write() unexpected error: %v", err)
+	}
+	output := b.String()
+
+	wantLines := []string{
+		"# TYPE jwks_mock_http_requests_total counter",
+		`jwks_mock_http_requests_total{method="GET",route="/.well-known/jwks.json",status="200"} 2`,
+		"# TYPE jwks_mock_http_request_duration_seconds histogram",
+		`jwks_mock_http_request_duration_seconds_bucket{method="GET",route="/.well-known/jwks.json",status="200",le="0.005"} 1`,
+		`jwks_mock_http_request_duration_seconds_bucket{method="GET",route="/.well-known/jwks.json",status="200",le="2.5"} 2`,
+		`jwks_mock_http_request_duration_seconds_bucket{method="GET",route="/.well-known/jwks.json",status="200",le="+Inf"} 2`,
+		`jwks_mock_http_request_duration_seconds_count{method="GET",route="/.well-known/jwks.json",status="200"} 2`,
+		`jwks_mock_tokens_issued_total{kid="key-1",alg="RS256"} 1`,
+		`jwks_mock_introspections_total{active="false",reason="expired"} 1`,
+		"# TYPE jwks_mock_keys gauge",
+		`jwks_mock_keys{issuer="http://localhost:3000"} 3`,
+		`jwks_mock_keys{issuer="http://\"quoted\""} 1`,
+		`jwks_mock_key_rotations_total{issuer="http://localhost:3000",event="added"} 1`,
+	}
+	for _, line := range wantLines {
+		if !strings.Contains(output, line+"\n") {
+			t.Errorf("Write() output is missing %q", line)
+		}
+	}
+
+	registry.UntrackKeys("http://localhost:3000")
+	b.Reset()
+	registry.Write(&b)
+	if strings.Contains(b.String(), `jwks_mock_keys{issuer="http://localhost:3000"}`) {
+		t.Error("Write() after UntrackKeys still reports the issuer")
+	}
+}
@@ -0,0 +1,251 @@
+package recording
+
+import (
+	"context"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Token decisions recorded for requests that present a token
+const (
+	DecisionActive   = "active"   // the token was accepted
+	DecisionInactive = "inactive" // the token was rejected, see Entry.Reason
+)
+
+// Entry records one request and the response the mock sent
+type Entry struct {
+	ID            int64       `json:"id"`
+	RequestID     string      `json:"request_id,omitempty"`
+	Time          time.Time   `json:"time"`
+	Method        string      `json:"method"`
+	Host          string      `json:"host"`
+	Path          string      `json:"path"`
+	Query         string      `json:"query,omitempty"`
+	Headers       http.Header `json:"headers"`
+	Body          string      `json:"body,omitempty"`
+	BodyTruncated bool        `json:"body_truncated,omitempty"`
+	Response      Response    `json:"response"`
+	DurationMs    float64     `json:"duration_ms"`
+
+	// The token a resource server presented for introspection or to /userinfo, and
+	// whether it was accepted
+	Token    string `json:"token,omitempty"`
+	Decision string `json:"decision,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Response records the response to a request
+type Response struct {
+	Status        int         `json:"status"`
+	Headers       http.Header `json:"headers"`
+	Body          string      `json:"body,omitempty"`
+	BodyTruncated bool        `json:"body_truncated,omitempty"`
+}
+
+// Redacted replaces credentials in recorded headers and bodies
+const Redacted = "[REDACTED]"
+
+// sensitiveHeaders carry credentials of the caller or of the mock's clients
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "X-API-Key", "DPoP", "Cookie", "Set-Cookie"}
+
+// sensitiveFields are form and JSON body fields holding passwords and client credentials
+var sensitiveFields = map[string]bool{
+	"password":                  true,
+	"client_secret":             true,
+	"client_assertion":          true,
+	"registration_access_token": true,
+}
+
+// redact replaces the credentials in the entry's headers and bodies
+func (e *Entry) redact() {
+	redactHeaders(e.Headers)
+	redactHeaders(e.Response.Headers)
+	e.Body = redactBody(e.Headers.Get("Content-Type"), e.Body)
+	e.Response.Body = redactBody(e.Response.Headers.Get("Content-Type"), e.Response.Body)
+}
+
+// redactHeaders replaces the values of credential headers
+func redactHeaders(header http.Header) {
+	for _, name := range sensitiveHeaders {
+		if values := header.Values(name); len(values) > 0 {
+			header.Set(name, Redacted)
+		}
+	}
+}
+
+// redactBody replaces sensitive fields of form and JSON bodies. Bodies that mention a
+// sensitive field but cannot be parsed, e.g. truncated ones, are replaced entirely.
+func redactBody(contentType, body string) string {
+	if body == "" {
+		return body
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		if values, err := url.ParseQuery(body); err == nil {
+			redacted := false
+			for name := range values {
+				if sensitiveFields[name] {
+					values.Set(name, Redacted)
+					redacted = true
+				}
+			}
+			if !redacted {
+				return body
+			}
+			return values.Encode()
+		}
+	case strings.HasSuffix(mediaType, "json"):
+		var object map[string]interface{}
+		if err := json.Unmarshal([]byte(body), &object); err == nil {
+			redacted := false
+			for name := range object {
+				if sensitiveFields[name] {
+					object[name] = Redacted
+					redacted = true
+				}
+			}
+			if !redacted {
+				return body
+			}
+			if encoded, err := json.Marshal(object); err == nil {
+				return string(encoded)
+			}
+		}
+	}
+
+	for name := range sensitiveFields {
+		if strings.Contains(body, name) {
+			return Redacted
+		}
+	}
+	return body
+}
+
+// Filter selects recorded entries. Zero fields match every entry.
+type Filter struct {
+	Method   string
+	Path     string // exact path, or a prefix ending in *
+	Status   int
+	Decision string
+	Since    int64 // only entries with a greater ID
+	Limit    int   // only the most recent entries
+}
+
+// matches reports whether the entry passes the filter
+func (f Filter) matches(entry *Entry) bool {
+	switch {
+	case f.Method != "" && !strings.EqualFold(f.Method, entry.Method):
+		return false
+	case f.Status != 0 && f.Status != entry.Response.Status:
+		return false
+	case f.Decision != "" && f.Decision != entry.Decision:
+		return false
+	case entry.ID <= f.Since:
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(f.Path, "*"); ok {
+		return strings.HasPrefix(entry.Path, prefix)
+	}
+	return f.Path == "" || f.Path == entry.Path
+}
+
+// Store keeps the most recent entries in a ring buffer, dropping the oldest when full
+type Store struct {
+	entries []*Entry
+	next    int // index the next entry is written to
+	count   int
+	lastID  int64
+	mu      sync.Mutex // Protect concurrent access to entries
+}
+
+// NewStore creates a store holding up to capacity entries. A store with no capacity
+// records nothing.
+func NewStore(capacity int) *Store {
+	if capacity < 0 {
+		capacity = 0
+	}
+	return &Store{entries: make([]*Entry, capacity)}
+}
+
+// Capacity returns the number of entries the store holds
+func (s *Store) Capacity() int {
+	return len(s.entries)
+}
+
+// Add redacts the credentials of the entry, assigns it an ID and records it, evicting the
+// oldest entry when the store is full
+func (s *Store) Add(entry *Entry) {
+	entry.redact()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastID++
+	entry.ID = s.lastID
+	if len(s.entries) == 0 {
+		return
+	}
+
+	s.entries[s.next] = entry
+	s.next = (s.next + 1) % len(s.entries)
+	if s.count < len(s.entries) {
+		s.count++
+	}
+}
+
+// List returns the entries passing the filter, oldest first
+func (s *Store) List(filter Filter) []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := []Entry{}
+	start := (s.next - s.count + len(s.entries)) % max(len(s.entries), 1)
+	for i := 0; i < s.count; i++ {
+		entry := s.entries[(start+i)%len(s.entries)]
+		if filter.matches(entry) {
+			list = append(list, *entry)
+		}
+	}
+
+	if filter.Limit > 0 && len(list) > filter.Limit {
+		list = list[len(list)-filter.Limit:]
+	}
+	return list
+}
+
+// Clear removes all entries and returns how many were removed. IDs keep increasing.
+func (s *Store) Clear() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cleared := s.count
+	clear(s.entries)
+	s.next = 0
+	s.count = 0
+	return cleared
+}
+
+// entryKey is the context key of the entry being recorded
+type entryKey struct{}
+
+// NewContext returns a context carrying the entry recorded for a request
+func NewContext(ctx context.Context, entry *Entry) context.Context {
+	return context.WithValue(ctx, entryKey{}, entry)
+}
+
+// RecordToken records the token presented with the request in ctx and the decision
+// about it. It does nothing when the request is not recorded.
+func RecordToken(ctx context.Context, token, decision, reason string) {
+	if entry, ok := ctx.Value(entryKey{}).(*Entry); ok {
+		entry.Token = token
+		entry.Decision = decision
+		entry.Reason = reason
+	}
+}
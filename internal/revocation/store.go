@@ -0,0 +1,133 @@
+package revocation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// Entry kinds identify how a revoked token is recorded
+const (
+	KindJTI       = "jti"
+	KindTokenHash = "token_hash"
+)
+
+// Entry represents a revoked token
+type Entry struct {
+	Kind      string    `json:"kind"`
+	Value     string    `json:"value"`
+	Subject   string    `json:"sub,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+	RevokedAt time.Time `json:"revoked_at"`
+}
+
+// Store records revoked tokens by jti, or by token hash for tokens without a jti
+type Store struct {
+	entries map[string]Entry
+	order   []string     // keys in revocation order, for stable listing
+	mu      sync.RWMutex // Protect concurrent access to entries
+}
+
+// NewStore creates a new revocation store
+func NewStore() *Store {
+	return &Store{
+		entries: make(map[string]Entry),
+	}
+}
+
+// HashToken returns the hex encoded SHA-256 hash used to record tokens without a jti
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// storeKey builds the map key for an entry
+func storeKey(kind, value string) string {
+	return kind + ":" + value
+}
+
+// Revoke records a token as revoked. The jti is used when present,
+// otherwise the token hash. Revoking an already revoked token is a no-op.
+// Entries of tokens that have expired are dropped, since expiry already rejects them.
+func (s *Store) Revoke(token, jti, subject string, expiresAt time.Time) Entry {
+	entry := Entry{
+		Kind:      KindJTI,
+		Value:     jti,
+		Subject:   subject,
+		ExpiresAt: expiresAt,
+		RevokedAt: time.Now().UTC(),
+	}
+	if jti == "" {
+		entry.Kind = KindTokenHash
+		entry.Value = HashToken(token)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removeExpired()
+
+	key := storeKey(entry.Kind, entry.Value)
+	if existing, ok := s.entries[key]; ok {
+		return existing
+	}
+
+	s.entries[key] = entry
+	s.order = append(s.order, key)
+	return entry
+}
+
+// IsRevoked reports whether a token has been revoked, by its jti or its hash
+func (s *Store) IsRevoked(token, jti string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if jti != "" {
+		if _, ok := s.entries[storeKey(KindJTI, jti)]; ok {
+			return true
+		}
+	}
+
+	_, ok := s.entries[storeKey(KindTokenHash, HashToken(token))]
+	return ok
+}
+
+// List returns the entries of revoked tokens that have not expired, in revocation order
+func (s *Store) List() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removeExpired()
+
+	entries := make([]Entry, 0, len(s.order))
+	for _, key := range s.order {
+		entries = append(entries, s.entries[key])
+	}
+	return entries
+}
+
+// Clear removes all entries and returns how many were removed
+func (s *Store) Clear() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := len(s.entries)
+	s.entries = make(map[string]Entry)
+	s.order = nil
+	return count
+}
+
+// removeExpired drops entries of expired tokens. Tokens without an expiry are kept.
+// Callers must hold the lock.
+func (s *Store) removeExpired() {
+	now := time.Now()
+	kept := s.order[:0]
+	for _, key := range s.order {
+		expiresAt := s.entries[key].ExpiresAt
+		if !expiresAt.IsZero() && now.After(expiresAt) {
+			delete(s.entries, key)
+			continue
+		}
+		kept = append(kept, key)
+	}
+	s.order = kept
+}
@@ -0,0 +1,98 @@
+package revocation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRevokeByJTI(t *testing.T) {
+	store := NewStore()
+	expiresAt := time.Now().Add(time.Hour)
+
+	entry := store.Revoke("token-a", "jti-a", "alice", expiresAt)
+	if entry.Kind != KindJTI || entry.Value != "jti-a" || entry.Subject != "alice" {
+		t.Errorf("Revoke() = %+v, want jti entry jti-a for alice", entry)
+	}
+	if !store.IsRevoked("another-token", "jti-a") {
+		t.Error("IsRevoked() of a token with a revoked jti = false, want true")
+	}
+	if store.IsRevoked("token-a", "jti-b") {
+		t.Error("IsRevoked() of a token with another jti = true, want false")
+	}
+
+	// Revoking again keeps the original entry
+	if again := store.Revoke("token-a", "jti-a", "bob", expiresAt); again != entry {
+		t.Errorf("Revoke() again = %+v, want %+v", again, entry)
+	}
+	if got := len(store.List()); got != 1 {
+		t.Errorf("len(List()) = %d, want 1", got)
+	}
+}
+
+func TestRevokeByTokenHash(t *testing.T) {
+	store := NewStore()
+
+	entry := store.Revoke("token-a", "", "alice", time.Now().Add(time.Hour))
+	if entry.Kind != KindTokenHash || entry.Value != HashToken("token-a") {
+		t.Errorf("Revoke() = %+v, want token hash entry %s", entry, HashToken("token-a"))
+	}
+	if !store.IsRevoked("token-a", "") {
+		t.Error("IsRevoked() of a revoked token = false, want true")
+	}
+	if store.IsRevoked("token-b", "") {
+		t.Error("IsRevoked() of another token = true, want false")
+	}
+}
+
+func TestListAndClear(t *testing.T) {
+	store := NewStore()
+	expiresAt := time.Now().Add(time.Hour)
+	store.Revoke("token-a", "jti-a", "alice", expiresAt)
+	store.Revoke("token-b", "", "bob", expiresAt)
+	store.Revoke("token-c", "jti-c", "carol", time.Time{})
+
+	entries := store.List()
+	want := []string{"jti-a", HashToken("token-b"), "jti-c"}
+	if len(entries) != len(want) {
+		t.Fatalf("len(List()) = %d, want %d", len(entries), len(want))
+	}
+	for i, entry := range entries {
+		if entry.Value != want[i] {
+			t.Errorf("List()[%d].Value = %s, want %s", i, entry.Value, want[i])
+		}
+	}
+
+	if cleared := store.Clear(); cleared != 3 {
+		t.Errorf("Clear() = %d, want 3", cleared)
+	}
+	if store.IsRevoked("token-a", "jti-a") {
+		t.Error("IsRevoked() after Clear() = true, want false")
+	}
+	if got := len(store.List()); got != 0 {
+		t.Errorf("len(List()) after Clear() = %d, want 0", got)
+	}
+}
+
+func TestExpiredEntriesDropped(t *testing.T) {
+	store := NewStore()
+	store.Revoke("token-a", "jti-a", "alice", time.Now().Add(-time.Second))
+	store.Revoke("token-b", "jti-b", "bob", time.Now().Add(time.Hour))
+
+	entries := store.List()
+	if len(entries) != 1 || entries[0].Value != "jti-b" {
+		t.Errorf("List() = %+v, want only jti-b", entries)
+	}
+	if store.IsRevoked("token-a", "jti-a") {
+		t.Error("IsRevoked() of an expired entry = true, want false")
+	}
+
+	// Revoking drops expired entries too
+	store.Revoke("token-c", "jti-c", "carol", time.Now().Add(-time.Second))
+	store.Revoke("token-d", "jti-d", "dave", time.Now().Add(time.Hour))
+	if store.IsRevoked("token-c", "jti-c") {
+		t.Error("IsRevoked() of an expired entry after Revoke() = true, want false")
+	}
+	if got := len(store.List()); got != 2 {
+		t.Errorf("len(List()) = %d, want 2", got)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package server
+
+import "os"
+
+// ownedByCurrentUser reports whether the file belongs to the user running the server.
+// Ownership cannot be checked here, so generated keys are never reused.
+func ownedByCurrentUser(info os.FileInfo) bool {
+	return false
+}
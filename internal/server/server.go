@@ -18,10 +18,13 @@ import (
 
 // Server represents the JWKS mock server
 type Server struct {
-	config     *config.Config
-	keyManager *keys.Manager
-	handler    *handlers.Handler
-	server     *http.Server
+	config      *config.Config
+	keyManager  *keys.Manager
+	handler     *handlers.Handler
+	server      *http.Server
+	httpServer  *http.Server // plain HTTP listener alongside HTTPS, when configured
+	adminServer *http.Server // admin endpoints listener, when configured
+	tenants     *tenantRegistry
 }
 
 // New creates a new server instance
@@ -43,21 +46,54 @@ func New(cfg *config.Config) (*Server, error) {
 		config:     cfg,
 		keyManager: keyManager,
 		handler:    handler,
+		tenants:    newTenantRegistry(),
+	}
+
+	// Initialize tenants defined in config
+	for _, tenantConfig := range cfg.Tenants {
+		t, err := server.newTenant(tenantConfig)
+		if err != nil {
+			return nil, err
+		}
+		if err := server.addTenant(t); err != nil {
+			return nil, fmt.Errorf("failed to add tenant %s: %w", t.name, err)
+		}
 	}
 
 	return server, nil
 }
 
-// Start starts the HTTP server
+// Start starts the HTTP server, serving HTTPS when TLS is enabled
 func (s *Server) Start() error {
-	router := s.setupRoutes()
+	publicRouter, adminRouter := s.setupRoutes()
 
-	s.server = &http.Server{
-		Addr:         fmt.Sprintf("%s:%d", s.config.Server.Host, s.config.Server.Port),
-		Handler:      router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+	s.server = newHTTPServer(s.config.Server.Host, s.config.Server.Port, publicRouter)
+	if adminRouter != nil {
+		s.adminServer = newHTTPServer(s.adminHost(), s.config.Admin.Port, adminRouter)
+	}
+
+	scheme := "http"
+	certFile := ""
+	if s.config.Server.TLS.Enabled {
+		tlsConfig, file, err := newTLSConfig(s.config)
+		if err != nil {
+			return err
+		}
+		s.server.TLSConfig = tlsConfig
+		if s.adminServer != nil {
+			s.adminServer.TLSConfig = tlsConfig
+		}
+		scheme = "https"
+		certFile = file
+
+		if s.config.Server.TLS.HTTPPort > 0 {
+			s.httpServer = newHTTPServer(s.config.Server.Host, s.config.Server.TLS.HTTPPort, publicRouter)
+		}
+	}
+	baseURL := fmt.Sprintf("%s://%s:%d", scheme, s.config.Server.Host, s.config.Server.Port)
+	adminURL := baseURL
+	if s.adminServer != nil {
+		adminURL = fmt.Sprintf("%s://%s", scheme, s.adminServer.Addr)
 	}
 
 	logger.Infof("Environment variables:")
@@ -67,21 +103,55 @@ func (s *Server) Start() error {
 	logger.Infof("HOST: %s", s.config.Server.Host)
 
 	logger.Infof("Keys initialized successfully: %v", s.keyManager.GetAllKeyIDs())
-	logger.Infof("JWT Dev Service starting on %s", s.server.Addr)
+	logger.Infof("JWT Dev Service starting on %s (%s)", s.server.Addr, scheme)
+	if certFile != "" {
+		logger.Infof("TLS certificate: %s", certFile)
+	}
+	if s.httpServer != nil {
+		logger.Infof("Plain HTTP listener: http://%s", s.httpServer.Addr)
+	}
+	if s.adminServer != nil {
+		logger.Infof("Admin listener: %s", adminURL)
+	}
 	logger.Infof("Available keys: %v", s.keyManager.GetAllKeyIDs())
-	logger.Infof("JWKS endpoint: http://%s:%d/.well-known/jwks.json", s.config.Server.Host, s.config.Server.Port)
-	logger.Infof("Generate token: POST http://%s:%d/generate-token", s.config.Server.Host, s.config.Server.Port)
-	logger.Infof("Generate invalid token: POST http://%s:%d/generate-invalid-token", s.config.Server.Host, s.config.Server.Port)
-	logger.Infof("Keys info: GET http://%s:%d/keys", s.config.Server.Host, s.config.Server.Port)
-	logger.Infof("Add key: POST http://%s:%d/keys", s.config.Server.Host, s.config.Server.Port)
-	logger.Infof("Remove key: DELETE http://%s:%d/keys/{kid}", s.config.Server.Host, s.config.Server.Port)
-
-	// Start server in a goroutine
-	go func() {
-		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Fatalf("Failed to start server: %v", err)
+	logger.Infof("JWKS endpoint: %s/.well-known/jwks.json", baseURL)
+	logger.Infof("Discovery: GET %s/.well-known/openid-configuration", baseURL)
+	logger.Infof("Authorize: GET/POST %s/authorize", baseURL)
+	logger.Infof("Pushed authorization request: POST %s/par", baseURL)
+	logger.Infof("Register client: POST %s/register", baseURL)
+	logger.Infof("Manage client: GET/PUT/DELETE %s/register/{client_id}", baseURL)
+	logger.Infof("Token endpoint: POST %s/token", baseURL)
+	logger.Infof("Device authorization: POST %s/device_authorization", baseURL)
+	logger.Infof("Device verification: GET %s/device", baseURL)
+	logger.Infof("Introspect token: POST %s/introspect", baseURL)
+	logger.Infof("Revoke token: POST %s/revoke", baseURL)
+	logger.Infof("UserInfo: GET/POST %s/userinfo", baseURL)
+	logger.Infof("End session: GET/POST %s/end_session", baseURL)
+	logger.Infof("Metrics: GET %s/metrics", baseURL)
+	logger.Infof("Generate token: POST %s/generate-token", adminURL)
+	logger.Infof("Generate invalid token: POST %s/generate-invalid-token", adminURL)
+	logger.Infof("Keys info: GET %s/keys", adminURL)
+	logger.Infof("Add key: POST %s/keys", adminURL)
+	logger.Infof("Remove key: DELETE %s/keys/{kid}", adminURL)
+	logger.Infof("Revoked tokens: GET/DELETE %s/revoked-tokens", adminURL)
+	logger.Infof("Logout deliveries: GET/DELETE %s/logout-deliveries", adminURL)
+	logger.Infof("Faults: GET/POST/DELETE %s/faults, DELETE %s/faults/{id}", adminURL, adminURL)
+	logger.Infof("Tenants: GET/POST %s/tenants, DELETE %s/tenants/{name}", adminURL, adminURL)
+	for _, t := range s.tenants.List() {
+		logger.Infof("Tenant %s: issuer %s, JWKS endpoint %s%s%s/.well-known/jwks.json", t.name, t.config.JWT.Issuer, baseURL, tenantPrefix, t.name)
+		if len(t.hosts) > 0 {
+			logger.Infof("Tenant %s virtual hosts: %v", t.name, t.hosts)
 		}
-	}()
+	}
+
+	// Start servers in goroutines
+	go serve(s.server, "server")
+	if s.httpServer != nil {
+		go serve(s.httpServer, "HTTP server")
+	}
+	if s.adminServer != nil {
+		go serve(s.adminServer, "admin server")
+	}
 
 	// Wait for interrupt signal to gracefully shutdown
 	s.waitForShutdown()
@@ -89,35 +159,175 @@ func (s *Server) Start() error {
 	return nil
 }
 
-// setupRoutes configures the HTTP routes
-func (s *Server) setupRoutes() *mux.Router {
+// serve runs an HTTP server until it is shut down, using TLS when it has a TLS configuration
+func serve(server *http.Server, name string) {
+	var err error
+	if server.TLSConfig != nil {
+		err = server.ListenAndServeTLS("", "")
+	} else {
+		err = server.ListenAndServe()
+	}
+	if err != nil && err != http.ErrServerClosed {
+		logger.Fatalf("Failed to start %s: %v", name, err)
+	}
+}
+
+// newHTTPServer creates an HTTP server for the router on the given address
+func newHTTPServer(host string, port int, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:         fmt.Sprintf("%s:%d", host, port),
+		Handler:      handler,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+}
+
+// adminHost returns the admin listener host, defaulting to the server host
+func (s *Server) adminHost() string {
+	if s.config.Admin.Host != "" {
+		return s.config.Admin.Host
+	}
+	return s.config.Server.Host
+}
+
+// setupRoutes configures the HTTP routes. With an admin port configured, the admin
+// endpoints are served by a separate router, otherwise both share the public router
+// and the returned admin router is nil. Requests for a tenant's virtual host are
+// routed to that tenant ahead of the top-level routes.
+func (s *Server) setupRoutes() (*mux.Router, *mux.Router) {
+	public := s.newRouter()
+	if s.config.Admin.Port == 0 {
+		s.addVirtualHostRoutes(public, nil)
+		addPublicRoutes(public, s.handler)
+		public.HandleFunc("/metrics", s.handler.Metrics).Methods("GET", "OPTIONS")
+		addAdminRoutes(public, s.handler)
+		s.addFaultRoutes(public)
+		s.addRecordingRoutes(public)
+		s.addTenantRoutes(public, public, false)
+		return public, nil
+	}
+
+	admin := s.newRouter()
+	s.addVirtualHostRoutes(public, admin)
+	addPublicRoutes(public, s.handler)
+	public.HandleFunc("/metrics", s.handler.Metrics).Methods("GET", "OPTIONS")
+	admin.HandleFunc("/health", s.handler.Health).Methods("GET", "OPTIONS")
+	addAdminRoutes(admin, s.handler)
+	s.addFaultRoutes(admin)
+	s.addRecordingRoutes(admin)
+	s.addTenantRoutes(public, admin, true)
+	return public, admin
+}
+
+// newRouter creates a router with the access logging and CORS middleware
+func (s *Server) newRouter() *mux.Router {
 	router := mux.NewRouter()
 
 	// Apply access logging middleware first
 	router.Use(s.handler.AccessLog)
-	
+
+	// Record requests as they reach the server, before any fault is injected
+	router.Use(s.handler.RecordRequests)
+
+	// Apply request metrics middleware
+	router.Use(s.handler.RecordMetrics)
+
 	// Apply CORS middleware
 	router.Use(s.handler.CORS)
 
+	// Apply fault injection last so injected faults still carry the CORS headers
+	router.Use(s.handler.FaultInjection)
+
+	return router
+}
+
+// addPublicRoutes adds the endpoints relying parties and resource servers use
+func addPublicRoutes(router *mux.Router, h *handlers.Handler) {
 	// JWKS endpoint
-	router.HandleFunc("/.well-known/jwks.json", s.handler.JWKS).Methods("GET", "OPTIONS")
+	router.HandleFunc("/.well-known/jwks.json", h.JWKS).Methods("GET", "OPTIONS")
 
-	// Token generation endpoints
-	router.HandleFunc("/generate-token", s.handler.GenerateToken).Methods("POST", "OPTIONS")
-	router.HandleFunc("/generate-invalid-token", s.handler.GenerateInvalidToken).Methods("POST", "OPTIONS")
+	// OpenID Connect discovery endpoint
+	router.HandleFunc("/.well-known/openid-configuration", h.OpenIDConfiguration).Methods("GET", "OPTIONS")
+
+	// Authorization endpoints (pushed authorization requests RFC 9126, request objects RFC 9101)
+	router.HandleFunc("/authorize", h.Authorize).Methods("GET", "POST", "OPTIONS")
+	router.HandleFunc("/par", h.PushedAuthorizationRequest).Methods("POST", "OPTIONS")
+
+	// Dynamic client registration endpoints (RFC 7591, RFC 7592)
+	router.HandleFunc("/register", h.RegisterClient).Methods("POST", "OPTIONS")
+	router.HandleFunc("/register/{client_id}", h.GetClientRegistration).Methods("GET", "OPTIONS")
+	router.HandleFunc("/register/{client_id}", h.UpdateClientRegistration).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/register/{client_id}", h.DeleteClientRegistration).Methods("DELETE", "OPTIONS")
+
+	// OAuth 2.0 token endpoint
+	router.HandleFunc("/token", h.Token).Methods("POST", "OPTIONS")
+
+	// Device authorization grant endpoints (OAuth 2.0 RFC 8628)
+	router.HandleFunc("/device_authorization", h.DeviceAuthorization).Methods("POST", "OPTIONS")
+	router.HandleFunc("/device", h.DeviceVerification).Methods("GET", "POST", "OPTIONS")
 
 	// Token introspection endpoint (OAuth 2.0 RFC 7662)
-	router.HandleFunc("/introspect", s.handler.Introspect).Methods("POST", "OPTIONS")
+	router.HandleFunc("/introspect", h.Introspect).Methods("POST", "OPTIONS")
+
+	// Token revocation endpoint (OAuth 2.0 RFC 7009)
+	router.HandleFunc("/revoke", h.Revoke).Methods("POST", "OPTIONS")
+
+	// OpenID Connect UserInfo endpoint
+	router.HandleFunc("/userinfo", h.UserInfo).Methods("GET", "POST", "OPTIONS")
+
+	// Logout endpoint (OpenID Connect RP-Initiated, Back-Channel and Front-Channel Logout)
+	router.HandleFunc("/end_session", h.EndSession).Methods("GET", "POST", "OPTIONS")
 
-	// Health and info endpoints
-	router.HandleFunc("/health", s.handler.Health).Methods("GET", "OPTIONS")
-	router.HandleFunc("/keys", s.handler.Keys).Methods("GET", "OPTIONS")
+	// Key listing endpoint
+	router.HandleFunc("/keys", h.Keys).Methods("GET", "OPTIONS")
+
+	// Health endpoint
+	router.HandleFunc("/health", h.Health).Methods("GET", "OPTIONS")
+}
+
+// addAdminRoutes adds the endpoints that mint tokens or change server state, behind admin authentication
+func addAdminRoutes(parent *mux.Router, h *handlers.Handler) {
+	router := parent.NewRoute().Subrouter()
+	router.Use(h.AdminAuth)
+
+	// Token generation endpoints
+	router.HandleFunc("/generate-token", h.GenerateToken).Methods("POST", "OPTIONS")
+	router.HandleFunc("/generate-invalid-token", h.GenerateInvalidToken).Methods("POST", "OPTIONS")
 
 	// Key management endpoints
-	router.HandleFunc("/keys", s.handler.AddKey).Methods("POST", "OPTIONS")
-	router.HandleFunc("/keys/{kid}", s.handler.RemoveKey).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/keys", h.AddKey).Methods("POST", "OPTIONS")
+	router.HandleFunc("/keys/{kid}", h.RemoveKey).Methods("DELETE", "OPTIONS")
 
-	return router
+	// Revocation store management endpoints
+	router.HandleFunc("/revoked-tokens", h.RevokedTokens).Methods("GET", "OPTIONS")
+	router.HandleFunc("/revoked-tokens", h.ClearRevokedTokens).Methods("DELETE", "OPTIONS")
+
+	// Logout delivery log endpoints
+	router.HandleFunc("/logout-deliveries", h.LogoutDeliveries).Methods("GET", "OPTIONS")
+	router.HandleFunc("/logout-deliveries", h.ClearLogoutDeliveries).Methods("DELETE", "OPTIONS")
+}
+
+// addFaultRoutes adds the fault injection endpoints. Faults apply to every route of the
+// server, tenant routes included, so they are only managed at the top level.
+func (s *Server) addFaultRoutes(parent *mux.Router) {
+	router := parent.NewRoute().Subrouter()
+	router.Use(s.handler.AdminAuth)
+
+	router.HandleFunc("/faults", s.handler.Faults).Methods("GET", "OPTIONS")
+	router.HandleFunc("/faults", s.handler.AddFault).Methods("POST", "OPTIONS")
+	router.HandleFunc("/faults", s.handler.ClearFaults).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/faults/{id}", s.handler.RemoveFault).Methods("DELETE", "OPTIONS")
+}
+
+// addRecordingRoutes adds the recorded request endpoints. Requests are recorded as
+// they reach the server, tenant requests included, so they are only inspected at the top level.
+func (s *Server) addRecordingRoutes(parent *mux.Router) {
+	router := parent.NewRoute().Subrouter()
+	router.Use(s.handler.AdminAuth)
+
+	router.HandleFunc(handlers.RecordedRequestsPath, s.handler.RecordedRequests).Methods("GET", "OPTIONS")
+	router.HandleFunc(handlers.RecordedRequestsPath, s.handler.ClearRecordedRequests).Methods("DELETE", "OPTIONS")
 }
 
 // waitForShutdown waits for interrupt signal and gracefully shuts down the server
@@ -134,6 +344,16 @@ func (s *Server) waitForShutdown() {
 	if err := s.server.Shutdown(ctx); err != nil {
 		logger.Fatalf("Server forced to shutdown: %v", err)
 	}
+	if s.httpServer != nil {
+		if err := s.httpServer.Shutdown(ctx); err != nil {
+			logger.Fatalf("HTTP server forced to shutdown: %v", err)
+		}
+	}
+	if s.adminServer != nil {
+		if err := s.adminServer.Shutdown(ctx); err != nil {
+			logger.Fatalf("Admin server forced to shutdown: %v", err)
+		}
+	}
 
 	logger.Info("HTTP server shutdown completed")
 }
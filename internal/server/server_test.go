@@ -0,0 +1,184 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/config"
+)
+
+// newTestServer creates a server with one key and the given admin port
+func newTestServer(t *testing.T, adminPort int) *Server {
+	t.Helper()
+
+	srv, err := New(&config.Config{
+		JWT:         config.JWTConfig{Issuer: "http://localhost:3000", Audience: "dev-api"},
+		InitialKeys: config.InitialKeysConfig{KeyIDs: []string{"test-key"}},
+		Admin:       config.AdminConfig{Port: adminPort},
+	})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	return srv
+}
+
+// routeStatus returns the status a router answers a request with
+func routeStatus(router *mux.Router, method, path string) int {
+	var body *strings.Reader
+	if method == http.MethodPost {
+		body = strings.NewReader(`{"claims": {"sub": "test"}}`)
+	} else {
+		body = strings.NewReader("")
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(method, path, body))
+	return rec.Code
+}
+
+func TestSetupRoutesSharedListener(t *testing.T) {
+	public, admin := newTestServer(t, 0).setupRoutes()
+	if admin != nil {
+		t.Fatal("setupRoutes() without an admin port returned an admin router")
+	}
+
+	for _, path := range []string{"/.well-known/jwks.json", "/keys", "/health"} {
+		if status := routeStatus(public, http.MethodGet, path); status != http.StatusOK {
+			t.Errorf("GET %s status = %d, want 200", path, status)
+		}
+	}
+	if status := routeStatus(public, http.MethodPost, "/generate-token"); status != http.StatusOK {
+		t.Errorf("POST /generate-token status = %d, want 200", status)
+	}
+}
+
+func TestSetupRoutesSeparateAdminListener(t *testing.T) {
+	public, admin := newTestServer(t, 3001).setupRoutes()
+	if admin == nil {
+		t.Fatal("setupRoutes() with an admin port returned no admin router")
+	}
+
+	tests := []struct {
+		method     string
+		path       string
+		publicCode int
+		adminCode  int
+	}{
+		{http.MethodGet, "/.well-known/jwks.json", http.StatusOK, http.StatusNotFound},
+		{http.MethodGet, "/.well-known/openid-configuration", http.StatusOK, http.StatusNotFound},
+		{http.MethodGet, "/health", http.StatusOK, http.StatusOK},
+		{http.MethodPost, "/generate-token", http.StatusNotFound, http.StatusOK},
+		{http.MethodGet, "/keys", http.StatusOK, http.StatusMethodNotAllowed},
+		{http.MethodPost, "/keys", http.StatusMethodNotAllowed, http.StatusBadRequest},
+		{http.MethodGet, "/revoked-tokens", http.StatusNotFound, http.StatusOK},
+		{http.MethodGet, "/admin/requests", http.StatusNotFound, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			if status := routeStatus(public, tt.method, tt.path); status != tt.publicCode {
+				t.Errorf("public status = %d, want %d", status, tt.publicCode)
+			}
+			if status := routeStatus(admin, tt.method, tt.path); status != tt.adminCode {
+				t.Errorf("admin status = %d, want %d", status, tt.adminCode)
+			}
+		})
+	}
+}
+
+func TestSetupRoutesAdminAuth(t *testing.T) {
+	srv := newTestServer(t, 0)
+	srv.config.Admin.RequireAuth = true
+	srv.config.Admin.APIKeys = []string{"admin-key"}
+	public, _ := srv.setupRoutes()
+
+	for _, path := range []string{"/.well-known/jwks.json", "/keys", "/health"} {
+		if status := routeStatus(public, http.MethodGet, path); status != http.StatusOK {
+			t.Errorf("GET %s status = %d, want 200", path, status)
+		}
+	}
+	if status := routeStatus(public, http.MethodGet, "/revoked-tokens"); status != http.StatusUnauthorized {
+		t.Errorf("GET /revoked-tokens without credentials status = %d, want 401", status)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/revoked-tokens", nil)
+	req.Header.Set("X-API-Key", "admin-key")
+	rec := httptest.NewRecorder()
+	public.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET /revoked-tokens with an API key status = %d, want 200", rec.Code)
+	}
+}
+
+func TestAdminAuthRejectsPublicGrantTokens(t *testing.T) {
+	srv := newTestServer(t, 0)
+	srv.config.Admin.RequireAuth = true
+	srv.config.Admin.Scope = "admin"
+	srv.config.Admin.ClientIDs = []string{"ops-cli"}
+	public, _ := srv.setupRoutes()
+
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		public.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Register a client, which is open to anyone
+	rec := serve(httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(`{"redirect_uris": ["https://app.example/cb"]}`)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST /register status = %d, want 201: %s", rec.Code, rec.Body.String())
+	}
+	var client struct {
+		ClientID     string `json:"client_id"`
+		ClientSecret string `json:"client_secret"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&client); err != nil {
+		t.Fatalf("failed to decode registration: %v", err)
+	}
+
+	// Ask for the admin scope, which the authorization endpoint approves automatically
+	query := url.Values{
+		"response_type": {"code"},
+		"client_id":     {client.ClientID},
+		"redirect_uri":  {"https://app.example/cb"},
+		"scope":         {"openid admin"},
+	}
+	rec = serve(httptest.NewRequest(http.MethodGet, "/authorize?"+query.Encode(), nil))
+	location, err := url.Parse(rec.Header().Get("Location"))
+	if rec.Code != http.StatusFound || err != nil || location.Query().Get("code") == "" {
+		t.Fatalf("GET /authorize status = %d, Location = %s", rec.Code, rec.Header().Get("Location"))
+	}
+
+	form := url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {location.Query().Get("code")},
+		"redirect_uri": {"https://app.example/cb"},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(client.ClientID, client.ClientSecret)
+	rec = serve(req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /token status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		Scope       string `json:"scope"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&token); err != nil {
+		t.Fatalf("failed to decode token response: %v", err)
+	}
+	if strings.Contains(token.Scope, "admin") {
+		t.Errorf("POST /token granted scope %q, want the admin scope removed", token.Scope)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/revoked-tokens", nil)
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	if rec = serve(req); rec.Code != http.StatusForbidden {
+		t.Errorf("GET /revoked-tokens with a public grant token status = %d, want 403", rec.Code)
+	}
+}
@@ -0,0 +1,366 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+	"github.com/shogotsuneto/jwks-mock-api/internal/keys"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/config"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/handlers"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/logger"
+)
+
+// tenantPrefix is the path under which each tenant's endpoints are served
+const tenantPrefix = "/tenants/"
+
+// tenant is an issuer served under /tenants/{name}, and at the root of its virtual hosts,
+// with its own keys and handler state
+type tenant struct {
+	name       string
+	hosts      []string // lowercase hostnames without port
+	config     *config.Config
+	keyManager *keys.Manager
+	handler    *handlers.Handler
+
+	// Routers for /tenants/{name}/... and for requests to the tenant's hosts. The admin
+	// routers are nil when the admin endpoints share the public listener.
+	public     *mux.Router
+	admin      *mux.Router
+	hostPublic *mux.Router
+	hostAdmin  *mux.Router
+}
+
+// tenantRegistry holds the tenants defined in config or created at runtime
+type tenantRegistry struct {
+	mu      sync.RWMutex // Protect concurrent access to tenants and hosts
+	tenants map[string]*tenant
+	hosts   map[string]*tenant
+}
+
+// TenantResponse describes a tenant in the tenant management API
+type TenantResponse struct {
+	Name      string   `json:"name"`
+	Hosts     []string `json:"hosts,omitempty"`
+	Issuer    string   `json:"issuer"`
+	Audience  string   `json:"audience"`
+	Profile   string   `json:"profile"`
+	KeyIDs    []string `json:"key_ids"`
+	JWKSURI   string   `json:"jwks_uri"`
+	Discovery string   `json:"discovery"`
+}
+
+// TenantErrorResponse represents a failed tenant management request
+type TenantErrorResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// Errors returned when a tenant name or host is already taken
+var (
+	errTenantExists = errors.New("tenant already exists")
+	errHostInUse    = errors.New("host is already used by another tenant")
+)
+
+// newTenant creates a tenant's keys, handler and routers. The tenant's routes live under
+// /tenants/{name} and split into public and admin routers like the top-level routes.
+func (s *Server) newTenant(tenantConfig config.TenantConfig) (*tenant, error) {
+	if err := tenantConfig.Validate(); err != nil {
+		return nil, err
+	}
+
+	cfg := s.config.ForTenant(tenantConfig)
+	keyManager := keys.NewManager()
+	if err := keyManager.GenerateKeys(cfg.InitialKeys.KeyIDs); err != nil {
+		return nil, fmt.Errorf("failed to generate keys for tenant %s: %w", tenantConfig.Name, err)
+	}
+	handler := handlers.New(cfg, keyManager)
+
+	t := &tenant{
+		name:       tenantConfig.Name,
+		config:     cfg,
+		keyManager: keyManager,
+		handler:    handler,
+	}
+	for _, host := range tenantConfig.Hosts {
+		t.hosts = append(t.hosts, config.HostName(host))
+	}
+	t.public, t.admin = s.tenantRouters(handler, tenantPrefix+tenantConfig.Name)
+	if len(t.hosts) > 0 {
+		t.hostPublic, t.hostAdmin = s.tenantRouters(handler, "")
+	}
+	return t, nil
+}
+
+// addTenant registers a tenant and reports its metrics with the top-level ones. The
+// registry is shared before the tenant is published, so no request sees it change, and
+// its key count is only tracked once its issuer is registered.
+func (s *Server) addTenant(t *tenant) error {
+	t.handler.ShareMetrics(s.handler)
+	if err := s.tenants.Add(t); err != nil {
+		t.handler.UnshareMetrics()
+		return err
+	}
+	t.handler.TrackKeyMetrics()
+	return nil
+}
+
+// tenantRouters creates a tenant's public and admin routers with the routes under prefix.
+// Without an admin port, the admin routes are added to the public router.
+func (s *Server) tenantRouters(handler *handlers.Handler, prefix string) (*mux.Router, *mux.Router) {
+	public := mux.NewRouter()
+	public.Use(handlers.RecordRoute(prefix, routeLabel(prefix)))
+	addPublicRoutes(withPrefix(public, prefix), handler)
+	if s.config.Admin.Port == 0 {
+		addAdminRoutes(withPrefix(public, prefix), handler)
+		return public, nil
+	}
+
+	admin := mux.NewRouter()
+	admin.Use(handlers.RecordRoute(prefix, routeLabel(prefix)))
+	withPrefix(admin, prefix).HandleFunc("/health", handler.Health).Methods("GET", "OPTIONS")
+	addAdminRoutes(withPrefix(admin, prefix), handler)
+	return public, admin
+}
+
+// routeLabel returns the prefix tenant routes are labelled with in the metrics, so all
+// tenants share the route templates
+func routeLabel(prefix string) string {
+	if prefix == "" {
+		return ""
+	}
+	return tenantPrefix + "{tenant}"
+}
+
+// withPrefix returns a subrouter for the routes under prefix, or the router itself without one
+func withPrefix(router *mux.Router, prefix string) *mux.Router {
+	if prefix == "" {
+		return router
+	}
+	return router.PathPrefix(prefix).Subrouter()
+}
+
+// newTenantRegistry creates an empty tenant registry
+func newTenantRegistry() *tenantRegistry {
+	return &tenantRegistry{
+		tenants: make(map[string]*tenant),
+		hosts:   make(map[string]*tenant),
+	}
+}
+
+// Add registers a tenant, failing if its name or one of its hosts is taken
+func (r *tenantRegistry) Add(t *tenant) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.tenants[t.name]; exists {
+		return errTenantExists
+	}
+	for _, host := range t.hosts {
+		if _, exists := r.hosts[host]; exists {
+			return errHostInUse
+		}
+	}
+
+	r.tenants[t.name] = t
+	for _, host := range t.hosts {
+		r.hosts[host] = t
+	}
+	return nil
+}
+
+// ByHost returns the tenant selected by a Host header value, or nil if there is none
+func (r *tenantRegistry) ByHost(host string) *tenant {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.hosts[config.HostName(host)]
+}
+
+// Get returns the tenant with the given name, or nil if there is none
+func (r *tenantRegistry) Get(name string) *tenant {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.tenants[name]
+}
+
+// Remove deletes a tenant and returns it, or nil if there was none
+func (r *tenantRegistry) Remove(name string) *tenant {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, exists := r.tenants[name]
+	if !exists {
+		return nil
+	}
+	delete(r.tenants, name)
+	for _, host := range t.hosts {
+		delete(r.hosts, host)
+	}
+	return t
+}
+
+// List returns the tenants sorted by name
+func (r *tenantRegistry) List() []*tenant {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tenants := make([]*tenant, 0, len(r.tenants))
+	for _, t := range r.tenants {
+		tenants = append(tenants, t)
+	}
+	sort.Slice(tenants, func(i, j int) bool { return tenants[i].name < tenants[j].name })
+	return tenants
+}
+
+// addVirtualHostRoutes passes requests whose Host header selects a tenant to the tenant's
+// host routers. It must be added before any other route so the tenant serves every path.
+// Requests no route of the tenant matches are labelled virtual_host in the metrics.
+func (s *Server) addVirtualHostRoutes(public, admin *mux.Router) {
+	matchHost := func(r *http.Request, match *mux.RouteMatch) bool {
+		return s.tenants.ByHost(r.Host) != nil
+	}
+	recordHost := handlers.RecordRoute("", "virtual_host")
+
+	public.MatcherFunc(matchHost).Handler(recordHost(s.serveTenantHost(false)))
+	if admin != nil {
+		admin.MatcherFunc(matchHost).Handler(recordHost(s.serveTenantHost(true)))
+	}
+}
+
+// serveTenantHost returns a handler passing requests to the public or admin host router of the tenant selected by the Host header
+func (s *Server) serveTenantHost(admin bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		t := s.tenants.ByHost(r.Host)
+		if t == nil {
+			writeTenantError(w, http.StatusNotFound, "No tenant serves host "+r.Host)
+			return
+		}
+		logger.AddFields(r.Context(), "tenant", t.name)
+
+		if admin {
+			t.hostAdmin.ServeHTTP(w, r)
+			return
+		}
+		t.hostPublic.ServeHTTP(w, r)
+	}
+}
+
+// addTenantRoutes adds the tenant management API to the admin router and dispatches
+// /tenants/{tenant}/... requests to the tenants' own routers. When the admin endpoints
+// have their own listener, tenant admin endpoints are only served there.
+func (s *Server) addTenantRoutes(public, admin *mux.Router, separateAdmin bool) {
+	management := admin.NewRoute().Subrouter()
+	management.Use(s.handler.AdminAuth)
+	management.HandleFunc("/tenants", s.listTenants).Methods("GET", "OPTIONS")
+	management.HandleFunc("/tenants", s.createTenant).Methods("POST", "OPTIONS")
+	management.HandleFunc("/tenants/{name}", s.deleteTenant).Methods("DELETE", "OPTIONS")
+
+	public.PathPrefix(tenantPrefix + "{tenant}/").HandlerFunc(s.serveTenant(false))
+	if separateAdmin {
+		admin.PathPrefix(tenantPrefix + "{tenant}/").HandlerFunc(s.serveTenant(true))
+	}
+}
+
+// serveTenant returns a handler passing requests to the public or admin router of the tenant in the path
+func (s *Server) serveTenant(admin bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := mux.Vars(r)["tenant"]
+		t := s.tenants.Get(name)
+		if t == nil {
+			writeTenantError(w, http.StatusNotFound, "Tenant not found: "+name)
+			return
+		}
+		logger.AddFields(r.Context(), "tenant", t.name)
+
+		if admin {
+			t.admin.ServeHTTP(w, r)
+			return
+		}
+		t.public.ServeHTTP(w, r)
+	}
+}
+
+// tenantResponse describes a tenant for the management API
+func tenantResponse(t *tenant) TenantResponse {
+	return TenantResponse{
+		Name:      t.name,
+		Hosts:     t.hosts,
+		Issuer:    t.config.JWT.Issuer,
+		Audience:  t.config.JWT.Audience,
+		Profile:   t.config.JWT.Profile,
+		KeyIDs:    t.keyManager.GetAllKeyIDs(),
+		JWKSURI:   t.config.JWT.Issuer + "/.well-known/jwks.json",
+		Discovery: t.config.JWT.Issuer + "/.well-known/openid-configuration",
+	}
+}
+
+// listTenants returns all tenants
+func (s *Server) listTenants(w http.ResponseWriter, r *http.Request) {
+	tenants := s.tenants.List()
+	response := make([]TenantResponse, 0, len(tenants))
+	for _, t := range tenants {
+		response = append(response, tenantResponse(t))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{"tenants": response})
+}
+
+// createTenant creates a tenant from a TenantConfig request body
+func (s *Server) createTenant(w http.ResponseWriter, r *http.Request) {
+	var tenantConfig config.TenantConfig
+	if err := json.NewDecoder(r.Body).Decode(&tenantConfig); err != nil {
+		writeTenantError(w, http.StatusBadRequest, fmt.Sprintf("Invalid JSON: %v", err))
+		return
+	}
+
+	t, err := s.newTenant(tenantConfig)
+	if err != nil {
+		writeTenantError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.addTenant(t); errors.Is(err, errHostInUse) {
+		writeTenantError(w, http.StatusConflict, "A host of the tenant is already in use: "+strings.Join(t.hosts, ", "))
+		return
+	} else if err != nil {
+		writeTenantError(w, http.StatusConflict, "Tenant already exists: "+t.name)
+		return
+	}
+
+	logger.InfoContext(r.Context(), "Created tenant", "tenant", t.name, "issuer", t.config.JWT.Issuer)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(tenantResponse(t))
+}
+
+// deleteTenant removes a tenant and its keys
+func (s *Server) deleteTenant(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	t := s.tenants.Remove(name)
+	if t == nil {
+		writeTenantError(w, http.StatusNotFound, "Tenant not found: "+name)
+		return
+	}
+	t.handler.ReleaseMetrics()
+
+	logger.InfoContext(r.Context(), "Deleted tenant", "tenant", name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeTenantError writes a failed tenant management response
+func writeTenantError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(TenantErrorResponse{
+		Success: false,
+		Message: message,
+	})
+}
@@ -0,0 +1,213 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shogotsuneto/jwks-mock-api/pkg/config"
+)
+
+func TestTenantRoutes(t *testing.T) {
+	srv := newTestServer(t, 0)
+	public, _ := srv.setupRoutes()
+
+	rec := httptest.NewRecorder()
+	public.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/tenants", strings.NewReader(`{"name": "svc-a", "audience": "svc-a-api"}`)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST /tenants status = %d, want 201: %s", rec.Code, rec.Body.String())
+	}
+	var created TenantResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if created.Issuer != "http://localhost:3000/tenants/svc-a" || created.Audience != "svc-a-api" {
+		t.Errorf("POST /tenants issuer = %s, audience = %s", created.Issuer, created.Audience)
+	}
+
+	tests := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{http.MethodGet, "/tenants/svc-a/.well-known/jwks.json", http.StatusOK},
+		{http.MethodGet, "/tenants/svc-a/.well-known/openid-configuration", http.StatusOK},
+		{http.MethodPost, "/tenants/svc-a/generate-token", http.StatusOK},
+		{http.MethodGet, "/tenants/svc-a/keys", http.StatusOK},
+		{http.MethodGet, "/tenants/svc-b/.well-known/jwks.json", http.StatusNotFound},
+		{http.MethodGet, "/tenants", http.StatusOK},
+	}
+	for _, tt := range tests {
+		if status := routeStatus(public, tt.method, tt.path); status != tt.want {
+			t.Errorf("%s %s status = %d, want %d", tt.method, tt.path, status, tt.want)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	public.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/tenants", strings.NewReader(`{"name": "svc-a"}`)))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("POST /tenants with a taken name status = %d, want 409", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	public.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/tenants", strings.NewReader(`{"name": "bad/name"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("POST /tenants with an invalid name status = %d, want 400", rec.Code)
+	}
+
+	if status := routeStatus(public, http.MethodDelete, "/tenants/svc-a"); status != http.StatusNoContent {
+		t.Errorf("DELETE /tenants/svc-a status = %d, want 204", status)
+	}
+	if status := routeStatus(public, http.MethodGet, "/tenants/svc-a/.well-known/jwks.json"); status != http.StatusNotFound {
+		t.Errorf("GET deleted tenant JWKS status = %d, want 404", status)
+	}
+}
+
+func TestTenantRoutesSeparateAdminListener(t *testing.T) {
+	srv := newTestServer(t, 3001)
+	tenant, err := srv.newTenant(config.TenantConfig{Name: "svc-a"})
+	if err != nil {
+		t.Fatalf("newTenant() unexpected error: %v", err)
+	}
+	srv.tenants.Add(tenant)
+	public, admin := srv.setupRoutes()
+
+	tests := []struct {
+		method     string
+		path       string
+		publicCode int
+		adminCode  int
+	}{
+		{http.MethodGet, "/tenants/svc-a/.well-known/jwks.json", http.StatusOK, http.StatusNotFound},
+		{http.MethodPost, "/tenants/svc-a/generate-token", http.StatusNotFound, http.StatusOK},
+		{http.MethodGet, "/tenants", http.StatusNotFound, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			if status := routeStatus(public, tt.method, tt.path); status != tt.publicCode {
+				t.Errorf("public status = %d, want %d", status, tt.publicCode)
+			}
+			if status := routeStatus(admin, tt.method, tt.path); status != tt.adminCode {
+				t.Errorf("admin status = %d, want %d", status, tt.adminCode)
+			}
+		})
+	}
+}
+
+func TestVirtualHostTenant(t *testing.T) {
+	srv := newTestServer(t, 0)
+	tenant, err := srv.newTenant(config.TenantConfig{Name: "tenant-a", Hosts: []string{"Auth.Tenant-A.local"}})
+	if err != nil {
+		t.Fatalf("newTenant() unexpected error: %v", err)
+	}
+	if err := srv.tenants.Add(tenant); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+	public, _ := srv.setupRoutes()
+
+	discovery := func(host string) map[string]interface{} {
+		req := httptest.NewRequest(http.MethodGet, "/.well-known/openid-configuration", nil)
+		req.Host = host
+		rec := httptest.NewRecorder()
+		public.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET discovery for %s status = %d, want 200", host, rec.Code)
+		}
+		var document map[string]interface{}
+		if err := json.NewDecoder(rec.Body).Decode(&document); err != nil {
+			t.Fatalf("failed to decode discovery: %v", err)
+		}
+		return document
+	}
+
+	tests := []struct {
+		host       string
+		wantIssuer string
+	}{
+		{"auth.tenant-a.local", "http://auth.tenant-a.local:3000"},
+		{"AUTH.tenant-a.local:3000", "http://auth.tenant-a.local:3000"},
+		{"localhost:3000", "http://localhost:3000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			document := discovery(tt.host)
+			if document["issuer"] != tt.wantIssuer {
+				t.Errorf("issuer = %v, want %s", document["issuer"], tt.wantIssuer)
+			}
+			if document["jwks_uri"] != tt.wantIssuer+"/.well-known/jwks.json" {
+				t.Errorf("jwks_uri = %v, want %s/.well-known/jwks.json", document["jwks_uri"], tt.wantIssuer)
+			}
+		})
+	}
+
+	// A second tenant cannot claim the same host
+	other, err := srv.newTenant(config.TenantConfig{Name: "tenant-b", Hosts: []string{"auth.tenant-a.local"}})
+	if err != nil {
+		t.Fatalf("newTenant() unexpected error: %v", err)
+	}
+	if err := srv.tenants.Add(other); err != errHostInUse {
+		t.Errorf("Add() error = %v, want %v", err, errHostInUse)
+	}
+
+	// Requests to the tenant's host are labelled with its route, or virtual_host without one
+	for _, path := range []string{"/.well-known/jwks.json", "/missing"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Host = "auth.tenant-a.local"
+		public.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	rec := httptest.NewRecorder()
+	public.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, want := range []string{
+		`jwks_mock_http_requests_total{method="GET",route="/.well-known/jwks.json",status="200"} 1`,
+		`jwks_mock_http_requests_total{method="GET",route="virtual_host",status="404"} 1`,
+	} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("GET /metrics missing %s:\n%s", want, rec.Body.String())
+		}
+	}
+
+	// Removing the tenant releases its host
+	srv.tenants.Remove("tenant-a")
+	if document := discovery("auth.tenant-a.local"); document["issuer"] != "http://localhost:3000" {
+		t.Errorf("issuer after removal = %v, want http://localhost:3000", document["issuer"])
+	}
+}
+
+func TestTenantMetrics(t *testing.T) {
+	srv := newTestServer(t, 0)
+	public, _ := srv.setupRoutes()
+
+	for _, name := range []string{"svc-m", "svc-m"} {
+		rec := httptest.NewRecorder()
+		public.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/tenants", strings.NewReader(`{"name": "`+name+`"}`)))
+		if rec.Code != http.StatusCreated && rec.Code != http.StatusConflict {
+			t.Fatalf("POST /tenants status = %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+	if status := routeStatus(public, http.MethodGet, "/tenants/svc-m/keys"); status != http.StatusOK {
+		t.Fatalf("GET /tenants/svc-m/keys status = %d, want 200", status)
+	}
+
+	rec := httptest.NewRecorder()
+	public.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`jwks_mock_http_requests_total{method="GET",route="/tenants/{tenant}/keys",status="200"} 1`,
+		`jwks_mock_keys{issuer="http://localhost:3000/tenants/svc-m"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("GET /metrics missing %s:\n%s", want, body)
+		}
+	}
+
+	// The conflicting tenant did not replace or remove the key count of the first one
+	if status := routeStatus(public, http.MethodDelete, "/tenants/svc-m"); status != http.StatusNoContent {
+		t.Fatalf("DELETE /tenants/svc-m status = %d, want 204", status)
+	}
+	rec = httptest.NewRecorder()
+	public.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if strings.Contains(rec.Body.String(), "tenants/svc-m\"}") {
+		t.Errorf("GET /metrics still reports the deleted tenant's keys:\n%s", rec.Body.String())
+	}
+}
@@ -0,0 +1,232 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shogotsuneto/jwks-mock-api/pkg/config"
+)
+
+// generatedCertValidity is how long an auto-generated certificate is valid
+const generatedCertValidity = 365 * 24 * time.Hour
+
+// defaultGeneratedCertFile returns where the auto-generated certificate is written for
+// clients to trust: the user's cache directory, which other users cannot write to
+func defaultGeneratedCertFile() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find a directory for the generated certificate, set generated_cert_file: %w", err)
+	}
+	return filepath.Join(dir, "jwks-mock-api", "jwks-mock-api.crt"), nil
+}
+
+// newTLSConfig builds the HTTPS listener configuration from the configured certificate,
+// or from a self-signed certificate generated for the configured hostnames
+func newTLSConfig(cfg *config.Config) (*tls.Config, string, error) {
+	tlsCfg := cfg.Server.TLS
+
+	var (
+		cert     tls.Certificate
+		certFile string
+		err      error
+	)
+	if tlsCfg.CertFile != "" || tlsCfg.KeyFile != "" {
+		cert, err = tls.LoadX509KeyPair(tlsCfg.CertFile, tlsCfg.KeyFile)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		certFile = tlsCfg.CertFile
+	} else {
+		certFile = tlsCfg.GeneratedCertFile
+		if certFile == "" {
+			if certFile, err = defaultGeneratedCertFile(); err != nil {
+				return nil, "", err
+			}
+		}
+		cert, err = loadOrGenerateCertificate(certificateHostnames(cfg), certFile)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+		// Client certificates are optional and checked per client for tls_client_auth (RFC 8705)
+		ClientAuth: tls.RequestClientCert,
+	}
+	if tlsCfg.ClientCAFile != "" {
+		caPEM, err := os.ReadFile(tlsCfg.ClientCAFile)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		tlsConfig.ClientCAs = x509.NewCertPool()
+		if !tlsConfig.ClientCAs.AppendCertsFromPEM(caPEM) {
+			return nil, "", fmt.Errorf("no certificates found in client CA file %s", tlsCfg.ClientCAFile)
+		}
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return tlsConfig, certFile, nil
+}
+
+// certificateHostnames returns the names a generated certificate covers: localhost,
+// the issuer host, the configured hostnames and the hosts and issuer hosts of the tenants
+func certificateHostnames(cfg *config.Config) []string {
+	names := []string{"localhost", "127.0.0.1", "::1"}
+	if issuer, err := url.Parse(cfg.JWT.Issuer); err == nil && issuer.Hostname() != "" {
+		names = append(names, issuer.Hostname())
+	}
+	names = append(names, cfg.Server.TLS.Hostnames...)
+	for _, tenant := range cfg.Tenants {
+		for _, host := range tenant.Hosts {
+			names = append(names, config.HostName(host))
+		}
+		if issuer, err := url.Parse(tenant.Issuer); err == nil && issuer.Hostname() != "" {
+			names = append(names, issuer.Hostname())
+		}
+	}
+	return names
+}
+
+// generatedKeyFile returns where the key of a generated certificate is kept: next to the
+// certificate, with a .key extension
+func generatedKeyFile(certFile string) string {
+	if ext := filepath.Ext(certFile); ext != ".key" {
+		return strings.TrimSuffix(certFile, ext) + ".key"
+	}
+	return certFile + ".key"
+}
+
+// loadOrGenerateCertificate reuses the certificate previously generated at certFile while
+// it is valid and covers the hostnames, so clients keep trusting it across restarts, and
+// generates a new one otherwise. A key file that another user could have written or read
+// is never reused.
+func loadOrGenerateCertificate(hostnames []string, certFile string) (tls.Certificate, error) {
+	keyFile := generatedKeyFile(certFile)
+	if privateFile(keyFile) {
+		if cert, err := tls.LoadX509KeyPair(certFile, keyFile); err == nil && coversHostnames(cert, hostnames) {
+			return cert, nil
+		}
+	}
+	return generateCertificate(hostnames, certFile)
+}
+
+// privateFile reports whether path is a regular file owned by the current user and
+// accessible only by them
+func privateFile(path string) bool {
+	info, err := os.Lstat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Mode().Perm() != 0o600 {
+		return false
+	}
+	return ownedByCurrentUser(info)
+}
+
+// coversHostnames reports whether a certificate is a currently valid leaf for every hostname
+func coversHostnames(cert tls.Certificate, hostnames []string) bool {
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil || leaf.IsCA {
+		return false
+	}
+	now := time.Now()
+	if now.Before(leaf.NotBefore) || now.After(leaf.NotAfter) {
+		return false
+	}
+	for _, name := range hostnames {
+		if name != "" && leaf.VerifyHostname(name) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// generateCertificate creates a self-signed certificate for the hostnames and writes it
+// as PEM to certFile so clients can add it to their trusted roots. The certificate is not
+// a CA, so trusting it does not let its key vouch for other names. The key is written
+// next to it, readable only by the owner, for the certificate to be reused.
+func generateCertificate(hostnames []string, certFile string) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to generate TLS key: %w", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to generate certificate serial number: %w", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "JWKS Mock API", Organization: []string{"jwks-mock-api"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(generatedCertValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	seen := make(map[string]bool)
+	for _, name := range hostnames {
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		if ip := net.ParseIP(name); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, name)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to create TLS certificate: %w", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := os.MkdirAll(filepath.Dir(certFile), 0o755); err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to create certificate directory: %w", err)
+	}
+	if err := replaceFile(certFile, certPEM, 0o644); err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to write TLS certificate: %w", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to encode TLS key: %w", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := replaceFile(generatedKeyFile(certFile), keyPEM, 0o600); err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to write TLS key: %w", err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
+
+// replaceFile writes data to a new file at path created with perm. An existing file is
+// removed first, as writing to it would keep its owner and permissions.
+func replaceFile(path string, data []byte, perm os.FileMode) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
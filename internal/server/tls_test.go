@@ -0,0 +1,181 @@
+package server
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shogotsuneto/jwks-mock-api/pkg/config"
+)
+
+func TestNewTLSConfigGeneratesTrustableCertificate(t *testing.T) {
+	certFile := filepath.Join(t.TempDir(), "certs", "jwks.crt")
+	cfg := &config.Config{
+		JWT: config.JWTConfig{Issuer: "https://jwks.test:3000"},
+		Server: config.ServerConfig{TLS: config.TLSConfig{
+			Enabled:           true,
+			Hostnames:         []string{"jwks-api", "10.0.0.5"},
+			GeneratedCertFile: certFile,
+		}},
+		Tenants: []config.TenantConfig{
+			{Name: "tenant-a", Hosts: []string{"Tenant-A.test:3000"}},
+			{Name: "tenant-b", Issuer: "https://tenant-b.test"},
+		},
+	}
+
+	tlsConfig, written, err := newTLSConfig(cfg)
+	if err != nil {
+		t.Fatalf("newTLSConfig() unexpected error: %v", err)
+	}
+	if written != certFile || len(tlsConfig.Certificates) != 1 {
+		t.Fatalf("newTLSConfig() cert file = %s, certificates = %d", written, len(tlsConfig.Certificates))
+	}
+
+	data, err := os.ReadFile(certFile)
+	if err != nil {
+		t.Fatalf("generated certificate was not written: %v", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		t.Fatal("generated certificate is not PEM")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse generated certificate: %v", err)
+	}
+
+	if cert.IsCA || cert.KeyUsage&x509.KeyUsageCertSign != 0 {
+		t.Error("generated certificate can sign other certificates")
+	}
+
+	// Clients trusting the written certificate can verify every configured name
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	for _, name := range []string{"localhost", "127.0.0.1", "jwks.test", "jwks-api", "10.0.0.5", "tenant-a.test", "tenant-b.test"} {
+		if _, err := cert.Verify(x509.VerifyOptions{DNSName: name, Roots: roots}); err != nil {
+			t.Errorf("certificate does not verify for %s: %v", name, err)
+		}
+	}
+	if _, err := cert.Verify(x509.VerifyOptions{DNSName: "other.test", Roots: roots}); err == nil {
+		t.Error("certificate verified for a name it was not generated for")
+	}
+}
+
+func TestNewTLSConfigReusesGeneratedCertificate(t *testing.T) {
+	certFile := filepath.Join(t.TempDir(), "jwks.crt")
+	cfg := &config.Config{
+		JWT: config.JWTConfig{Issuer: "https://localhost:3000"},
+		Server: config.ServerConfig{TLS: config.TLSConfig{
+			Enabled:           true,
+			Hostnames:         []string{"jwks-api"},
+			GeneratedCertFile: certFile,
+		}},
+	}
+
+	certificate := func() []byte {
+		t.Helper()
+		tlsConfig, _, err := newTLSConfig(cfg)
+		if err != nil {
+			t.Fatalf("newTLSConfig() unexpected error: %v", err)
+		}
+		return tlsConfig.Certificates[0].Certificate[0]
+	}
+
+	keyFile := filepath.Join(filepath.Dir(certFile), "jwks.key")
+	keyPermissions := func() os.FileMode {
+		t.Helper()
+		info, err := os.Stat(keyFile)
+		if err != nil {
+			t.Fatalf("generated key was not written: %v", err)
+		}
+		return info.Mode().Perm()
+	}
+
+	first := certificate()
+	if perm := keyPermissions(); perm != 0o600 {
+		t.Errorf("generated key permissions = %o, want 600", perm)
+	}
+
+	// A restart keeps the certificate clients already trust
+	if second := certificate(); !bytes.Equal(first, second) {
+		t.Error("newTLSConfig() generated a new certificate although the previous one covers the hostnames")
+	}
+
+	// A key others can read is not reused, and its replacement is private again
+	if err := os.Chmod(keyFile, 0o644); err != nil {
+		t.Fatalf("failed to change key permissions: %v", err)
+	}
+	if second := certificate(); bytes.Equal(first, second) {
+		t.Error("newTLSConfig() reused a key readable by other users")
+	}
+	if perm := keyPermissions(); perm != 0o600 {
+		t.Errorf("replaced key permissions = %o, want 600", perm)
+	}
+	first = certificate()
+
+	// A hostname the certificate does not cover replaces it
+	cfg.Server.TLS.Hostnames = append(cfg.Server.TLS.Hostnames, "jwks-api.internal")
+	third := certificate()
+	if bytes.Equal(first, third) {
+		t.Fatal("newTLSConfig() reused a certificate that does not cover a new hostname")
+	}
+	cert, err := x509.ParseCertificate(third)
+	if err != nil {
+		t.Fatalf("failed to parse generated certificate: %v", err)
+	}
+	if err := cert.VerifyHostname("jwks-api.internal"); err != nil {
+		t.Errorf("regenerated certificate does not cover the new hostname: %v", err)
+	}
+}
+
+func TestNewTLSConfigLoadsCertificateFiles(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{TLS: config.TLSConfig{
+			Enabled:  true,
+			CertFile: filepath.Join(t.TempDir(), "missing.crt"),
+			KeyFile:  filepath.Join(t.TempDir(), "missing.key"),
+		}},
+	}
+
+	if _, _, err := newTLSConfig(cfg); err == nil {
+		t.Error("newTLSConfig() with missing certificate files expected error")
+	}
+}
+
+func TestNewTLSConfigVerifiesClientCertificates(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{
+		Server: config.ServerConfig{TLS: config.TLSConfig{
+			Enabled:           true,
+			GeneratedCertFile: filepath.Join(dir, "jwks.crt"),
+		}},
+	}
+
+	// Without client CAs certificates are requested but not verified
+	tlsConfig, _, err := newTLSConfig(cfg)
+	if err != nil {
+		t.Fatalf("newTLSConfig() unexpected error: %v", err)
+	}
+	if tlsConfig.ClientAuth != tls.RequestClientCert || tlsConfig.ClientCAs != nil {
+		t.Errorf("newTLSConfig() ClientAuth = %v, want RequestClientCert without client CAs", tlsConfig.ClientAuth)
+	}
+
+	// The generated certificate stands in for a client CA bundle
+	cfg.Server.TLS.ClientCAFile = cfg.Server.TLS.GeneratedCertFile
+	tlsConfig, _, err = newTLSConfig(cfg)
+	if err != nil {
+		t.Fatalf("newTLSConfig() with a client CA file unexpected error: %v", err)
+	}
+	if tlsConfig.ClientAuth != tls.VerifyClientCertIfGiven || tlsConfig.ClientCAs == nil {
+		t.Errorf("newTLSConfig() ClientAuth = %v, want VerifyClientCertIfGiven with client CAs", tlsConfig.ClientAuth)
+	}
+
+	cfg.Server.TLS.ClientCAFile = filepath.Join(dir, "missing.pem")
+	if _, _, err := newTLSConfig(cfg); err == nil {
+		t.Error("newTLSConfig() with a missing client CA file expected error")
+	}
+}
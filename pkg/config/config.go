@@ -2,7 +2,10 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -11,22 +14,206 @@ import (
 
 // Config holds all configuration for the JWKS mock service
 type Config struct {
-	Server      ServerConfig      `yaml:"server"`
-	JWT         JWTConfig         `yaml:"jwt"`
-	InitialKeys InitialKeysConfig `yaml:"initial_keys"`
-	LogLevel    string            `yaml:"log_level"`
+	Server        ServerConfig        `yaml:"server"`
+	JWT           JWTConfig           `yaml:"jwt"`
+	JWKS          JWKSConfig          `yaml:"jwks"`
+	InitialKeys   InitialKeysConfig   `yaml:"initial_keys"`
+	LogLevel      string              `yaml:"log_level"`
+	LogFormat     string              `yaml:"log_format"`
+	LogOutput     string              `yaml:"log_output"`
+	Users         []UserConfig        `yaml:"users"`
+	Introspection IntrospectionConfig `yaml:"introspection"`
+	DeviceFlow    DeviceFlowConfig    `yaml:"device_flow"`
+	Clients       []ClientConfig      `yaml:"clients"`
+	Registration  RegistrationConfig  `yaml:"registration"`
+	DPoP          DPoPConfig          `yaml:"dpop"`
+	Admin         AdminConfig         `yaml:"admin"`
+	Tenants       []TenantConfig      `yaml:"tenants"`
+	Recording     RecordingConfig     `yaml:"recording"`
 }
 
+// Access token profiles for JWTConfig.Profile
+const (
+	ProfileJWT     = "jwt"     // plain JWTs, the default
+	ProfileRFC9068 = "rfc9068" // JWT profile for OAuth 2.0 access tokens (RFC 9068)
+)
+
+// Log formats for Config.LogFormat
+const (
+	LogFormatText = "text" // "[INFO] message" lines, the default
+	LogFormatJSON = "json" // one JSON object per line
+)
+
+// defaultIssuer is the issuer used unless one is configured
+const defaultIssuer = "http://localhost:3000"
+
 // ServerConfig holds server-related configuration
 type ServerConfig struct {
-	Port int    `yaml:"port"`
-	Host string `yaml:"host"`
+	Port int       `yaml:"port"`
+	Host string    `yaml:"host"`
+	TLS  TLSConfig `yaml:"tls"`
+}
+
+// TLSConfig holds the HTTPS listener configuration. When enabled, the server port serves
+// HTTPS with the given certificate, or with a self-signed certificate generated at startup.
+type TLSConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+
+	// Hostnames and IP addresses of the generated certificate, in addition to localhost
+	// and the issuer host, and the file the certificate is written to for clients to trust
+	Hostnames         []string `yaml:"hostnames"`
+	GeneratedCertFile string   `yaml:"generated_cert_file"`
+
+	HTTPPort int `yaml:"http_port"` // also serve plain HTTP on this port, 0 disables it
+
+	// PEM bundle of the CAs issuing client certificates. Certificates presented by clients
+	// are verified against it, and tls_client_auth clients can only be matched by subject
+	// DN with a verified certificate (RFC 8705 section 2.1); without it only thumbprints match.
+	ClientCAFile string `yaml:"client_ca_file"`
+}
+
+// AdminConfig holds the admin listener configuration. Admin endpoints mint tokens and
+// manage keys and server state; without a port they share the public listener.
+type AdminConfig struct {
+	Host string `yaml:"host"` // defaults to the server host
+	Port int    `yaml:"port"` // 0 serves the admin endpoints on the public port
+
+	// RequireAuth protects the admin endpoints; callers present an API key in the X-API-Key
+	// header, basic auth credentials of an admin user, or a bearer token issued by this
+	// service to one of ClientIDs that grants Scope. When false the endpoints stay open.
+	RequireAuth bool              `yaml:"require_auth"`
+	APIKeys     []string          `yaml:"api_keys"`
+	Users       []AdminUserConfig `yaml:"users"`
+	Scope       string            `yaml:"scope"`
+	ClientIDs   []string          `yaml:"client_ids"`
+}
+
+// RegistrationConfig holds the limits of dynamic client registration. Registration needs no
+// authentication, so the URIs the server itself requests for a registered client (jwks_uri,
+// request_uris and backchannel_logout_uri) must point at one of FetchHosts; with none, such
+// URIs are only accepted for clients defined in config.
+type RegistrationConfig struct {
+	FetchHosts []string `yaml:"fetch_hosts"` // host names, matched without the port
+}
+
+// AdminUserConfig holds basic auth credentials for the admin endpoints
+type AdminUserConfig struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// RecordingConfig holds the request recording served at /admin/requests
+type RecordingConfig struct {
+	Capacity     int `yaml:"capacity"`       // most recent requests kept, 0 disables recording
+	MaxBodyBytes int `yaml:"max_body_bytes"` // request and response bodies are truncated to this size
+}
+
+// TenantConfig describes an issuer served under /tenants/{name} with its own keys and state,
+// and at the root of any of its Hosts. Unset fields inherit the top-level settings, except
+// that the issuer defaults to the first host, or to the top-level issuer followed by
+// /tenants/{name}, and the key IDs are prefixed with the name.
+type TenantConfig struct {
+	Name     string         `yaml:"name" json:"name"`
+	Hosts    []string       `yaml:"hosts" json:"hosts,omitempty"` // Host header values selecting the tenant
+	Issuer   string         `yaml:"issuer" json:"issuer,omitempty"`
+	Audience string         `yaml:"audience" json:"audience,omitempty"`
+	Profile  string         `yaml:"profile" json:"profile,omitempty"`
+	KeyIDs   []string       `yaml:"key_ids" json:"key_ids,omitempty"`
+	Users    []UserConfig   `yaml:"users" json:"users,omitempty"`
+	Clients  []ClientConfig `yaml:"clients" json:"clients,omitempty"`
+}
+
+// tenantNamePattern limits tenant names to a single URL path segment
+var tenantNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Validate checks the tenant name and profile
+func (t *TenantConfig) Validate() error {
+	if !tenantNamePattern.MatchString(t.Name) {
+		return fmt.Errorf("invalid tenant name %q, expected letters, digits, '.', '_' or '-'", t.Name)
+	}
+	if t.Profile != "" && t.Profile != ProfileJWT && t.Profile != ProfileRFC9068 {
+		return fmt.Errorf("unsupported jwt profile %q for tenant %s, expected %q or %q", t.Profile, t.Name, ProfileJWT, ProfileRFC9068)
+	}
+	for _, host := range t.Hosts {
+		if host == "" || strings.ContainsAny(host, "/?#@ ") {
+			return fmt.Errorf("invalid host %q for tenant %s", host, t.Name)
+		}
+	}
+	return nil
+}
+
+// HostName lowercases a Host header value and strips its port, giving the name tenants
+// are selected by
+func HostName(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(host)
+}
+
+// virtualHostIssuer returns the issuer of a tenant selected by host, keeping the scheme
+// and port of the top-level issuer unless the host names its own port
+func virtualHostIssuer(issuer, host string) string {
+	scheme := "http"
+	parsed, err := url.Parse(issuer)
+	if err == nil && parsed.Scheme != "" {
+		scheme = parsed.Scheme
+	}
+	if err == nil && parsed.Port() != "" && !strings.Contains(host, ":") {
+		host += ":" + parsed.Port()
+	}
+	return scheme + "://" + strings.ToLower(host)
+}
+
+// ForTenant returns the configuration a tenant's handler runs with, filling in the
+// tenant's unset fields from this configuration
+func (c *Config) ForTenant(tenant TenantConfig) *Config {
+	tenantConfig := *c
+	tenantConfig.Tenants = nil
+
+	tenantConfig.JWT.Issuer = tenant.Issuer
+	if tenantConfig.JWT.Issuer == "" && len(tenant.Hosts) > 0 {
+		tenantConfig.JWT.Issuer = virtualHostIssuer(c.JWT.Issuer, tenant.Hosts[0])
+	} else if tenantConfig.JWT.Issuer == "" {
+		tenantConfig.JWT.Issuer = strings.TrimSuffix(c.JWT.Issuer, "/") + "/tenants/" + tenant.Name
+	}
+	if tenant.Audience != "" {
+		tenantConfig.JWT.Audience = tenant.Audience
+	}
+	if tenant.Profile != "" {
+		tenantConfig.JWT.Profile = tenant.Profile
+	}
+
+	keyIDs := tenant.KeyIDs
+	if len(keyIDs) == 0 {
+		for _, kid := range c.InitialKeys.KeyIDs {
+			keyIDs = append(keyIDs, tenant.Name+"-"+kid)
+		}
+	}
+	tenantConfig.InitialKeys = InitialKeysConfig{Count: len(keyIDs), KeyIDs: keyIDs}
+
+	if tenant.Users != nil {
+		tenantConfig.Users = tenant.Users
+	}
+	if tenant.Clients != nil {
+		tenantConfig.Clients = tenant.Clients
+	}
+	return &tenantConfig
 }
 
 // JWTConfig holds JWT-related configuration
 type JWTConfig struct {
 	Issuer   string `yaml:"issuer"`
 	Audience string `yaml:"audience"`
+	Profile  string `yaml:"profile"` // access token profile, ProfileJWT or ProfileRFC9068
+}
+
+// JWKSConfig holds the caching headers of the JWKS endpoint
+type JWKSConfig struct {
+	MaxAge  int  `yaml:"max_age"`  // Cache-Control max-age in seconds
+	NoStore bool `yaml:"no_store"` // send Cache-Control: no-store instead of a max-age
 }
 
 // InitialKeysConfig holds initial key generation configuration
@@ -35,6 +222,109 @@ type InitialKeysConfig struct {
 	KeyIDs []string `yaml:"key_ids"`
 }
 
+// IntrospectionConfig holds the token introspection endpoint configuration
+type IntrospectionConfig struct {
+	// RequireAuth enforces protected resource authentication; when false the endpoint stays open
+	RequireAuth        bool                      `yaml:"require_auth"`
+	ProtectedResources []ProtectedResourceConfig `yaml:"protected_resources"`
+}
+
+// ProtectedResourceConfig holds the credentials a resource server uses to call the
+// introspection endpoint, either HTTP basic auth (client_id/client_secret) or a static bearer token
+type ProtectedResourceConfig struct {
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
+	BearerToken  string `yaml:"bearer_token"`
+}
+
+// DeviceFlowConfig holds the device authorization grant timing (RFC 8628)
+type DeviceFlowConfig struct {
+	ExpiresIn int `yaml:"expires_in"` // device code lifetime in seconds
+	Interval  int `yaml:"interval"`   // minimum polling interval in seconds, 0 disables slow_down
+}
+
+// DPoPConfig holds the DPoP proof validation settings (RFC 9449)
+type DPoPConfig struct {
+	RequireNonce  bool `yaml:"require_nonce"`  // challenge proofs without a current server nonce with use_dpop_nonce
+	ProofLifetime int  `yaml:"proof_lifetime"` // seconds a proof's iat may differ from the server time
+}
+
+// ClientConfig describes an OAuth client. Clients are loaded from config or registered at
+// runtime (RFC 7591), so the fields carry the registration metadata names in both formats.
+type ClientConfig struct {
+	ClientID     string   `yaml:"client_id" json:"client_id"`
+	ClientSecret string   `yaml:"client_secret" json:"client_secret,omitempty"` // empty for public clients
+	ClientName   string   `yaml:"client_name" json:"client_name,omitempty"`
+	RedirectURIs []string `yaml:"redirect_uris" json:"redirect_uris,omitempty"`
+
+	GrantTypes              []string `yaml:"grant_types" json:"grant_types,omitempty"`
+	ResponseTypes           []string `yaml:"response_types" json:"response_types,omitempty"`
+	TokenEndpointAuthMethod string   `yaml:"token_endpoint_auth_method" json:"token_endpoint_auth_method,omitempty"`
+	Scope                   string   `yaml:"scope" json:"scope,omitempty"`
+
+	// Client keys used to verify request objects and private_key_jwt assertions,
+	// either inline or fetched from a URL
+	JWKS    map[string]interface{} `yaml:"jwks" json:"jwks,omitempty"`
+	JWKSURI string                 `yaml:"jwks_uri" json:"jwks_uri,omitempty"`
+
+	// Request object URLs /authorize may fetch (RFC 9101 section 5.2.3)
+	RequestURIs []string `yaml:"request_uris" json:"request_uris,omitempty"`
+
+	// Client certificate accepted for tls_client_auth (RFC 8705): the subject DN in Go's
+	// formatting (e.g. "CN=client,O=Example") and/or the SHA-256 thumbprint, base64url or hex
+	TLSClientAuthSubjectDN  string `yaml:"tls_client_auth_subject_dn" json:"tls_client_auth_subject_dn,omitempty"`
+	TLSClientCertThumbprint string `yaml:"tls_client_certificate_thumbprint" json:"tls_client_certificate_thumbprint,omitempty"`
+
+	RequirePushedAuthorizationRequests bool `yaml:"require_pushed_authorization_requests" json:"require_pushed_authorization_requests,omitempty"`
+	RequireSignedRequestObject         bool `yaml:"require_signed_request_object" json:"require_signed_request_object,omitempty"`
+
+	// Logout (OpenID Connect RP-Initiated, Back-Channel and Front-Channel Logout)
+	PostLogoutRedirectURIs            []string `yaml:"post_logout_redirect_uris" json:"post_logout_redirect_uris,omitempty"`
+	BackchannelLogoutURI              string   `yaml:"backchannel_logout_uri" json:"backchannel_logout_uri,omitempty"`
+	FrontchannelLogoutURI             string   `yaml:"frontchannel_logout_uri" json:"frontchannel_logout_uri,omitempty"`
+	FrontchannelLogoutSessionRequired bool     `yaml:"frontchannel_logout_session_required" json:"frontchannel_logout_session_required,omitempty"`
+}
+
+// UserConfig describes a test user served by the UserInfo endpoint and the password grant
+type UserConfig struct {
+	Sub    string                 `yaml:"sub"`
+	Claims map[string]interface{} `yaml:"claims"` // profile claims such as name and email
+	Roles  []string               `yaml:"roles"`
+	Groups []string               `yaml:"groups"`
+
+	// Credentials and account state for the resource owner password credentials grant
+	Username        string `yaml:"username"` // defaults to sub when empty
+	Password        string `yaml:"password"`
+	Locked          bool   `yaml:"locked"`
+	Disabled        bool   `yaml:"disabled"`
+	PasswordExpired bool   `yaml:"password_expired"`
+}
+
+// FindUser returns the configured user with the given subject, or nil if none matches
+func (c *Config) FindUser(sub string) *UserConfig {
+	for i := range c.Users {
+		if c.Users[i].Sub == sub {
+			return &c.Users[i]
+		}
+	}
+	return nil
+}
+
+// FindUserByUsername returns the configured user with the given username, or nil if none matches.
+// Users without a username are matched by their sub.
+func (c *Config) FindUserByUsername(username string) *UserConfig {
+	for i := range c.Users {
+		name := c.Users[i].Username
+		if name == "" {
+			name = c.Users[i].Sub
+		}
+		if name == username {
+			return &c.Users[i]
+		}
+	}
+	return nil
+}
+
 // Load loads configuration from environment variables and optional config file
 func Load(configFile string) (*Config, error) {
 	// Default configuration
@@ -44,14 +334,32 @@ func Load(configFile string) (*Config, error) {
 			Host: "0.0.0.0",
 		},
 		JWT: JWTConfig{
-			Issuer:   "http://localhost:3000",
 			Audience: "dev-api",
+			Profile:  ProfileJWT,
+		},
+		JWKS: JWKSConfig{
+			MaxAge: 3600,
 		},
 		InitialKeys: InitialKeysConfig{
 			Count:  2,
 			KeyIDs: []string{"key-1", "key-2"},
 		},
-		LogLevel: "info",
+		LogLevel:  "info",
+		LogFormat: LogFormatText,
+		LogOutput: "stderr",
+		DeviceFlow: DeviceFlowConfig{
+			ExpiresIn: 600,
+			Interval:  5,
+		},
+		DPoP: DPoPConfig{
+			ProofLifetime: 300,
+		},
+		Admin: AdminConfig{
+			Scope: "admin",
+		},
+		Recording: RecordingConfig{
+			MaxBodyBytes: 64 * 1024,
+		},
 	}
 
 	// Load from config file if provided
@@ -64,6 +372,46 @@ func Load(configFile string) (*Config, error) {
 	// Override with environment variables
 	loadFromEnv(config)
 
+	// Without an explicit issuer, an HTTPS server issues tokens for its HTTPS URL. An
+	// issuer set in the file or environment is kept as is, even if it equals the default.
+	if config.JWT.Issuer == "" && config.Server.TLS.Enabled {
+		config.JWT.Issuer = fmt.Sprintf("https://localhost:%d", config.Server.Port)
+	} else if config.JWT.Issuer == "" {
+		config.JWT.Issuer = defaultIssuer
+	}
+
+	if config.JWT.Profile != ProfileJWT && config.JWT.Profile != ProfileRFC9068 {
+		return nil, fmt.Errorf("unsupported jwt profile %q, expected %q or %q", config.JWT.Profile, ProfileJWT, ProfileRFC9068)
+	}
+
+	if config.LogFormat != LogFormatText && config.LogFormat != LogFormatJSON {
+		return nil, fmt.Errorf("unsupported log format %q, expected %q or %q", config.LogFormat, LogFormatText, LogFormatJSON)
+	}
+
+	if config.Admin.Port != 0 && (config.Admin.Port == config.Server.Port || config.Admin.Port == config.Server.TLS.HTTPPort) {
+		return nil, fmt.Errorf("admin port %d must differ from the server ports", config.Admin.Port)
+	}
+
+	tenantNames := make(map[string]bool)
+	for i := range config.Tenants {
+		if err := config.Tenants[i].Validate(); err != nil {
+			return nil, err
+		}
+		if tenantNames[config.Tenants[i].Name] {
+			return nil, fmt.Errorf("duplicate tenant %q", config.Tenants[i].Name)
+		}
+		tenantNames[config.Tenants[i].Name] = true
+	}
+	tenantHosts := make(map[string]bool)
+	for _, tenant := range config.Tenants {
+		for _, host := range tenant.Hosts {
+			if tenantHosts[HostName(host)] {
+				return nil, fmt.Errorf("host %q is used by more than one tenant", host)
+			}
+			tenantHosts[HostName(host)] = true
+		}
+	}
+
 	return config, nil
 }
 
@@ -89,6 +437,82 @@ func loadFromEnv(config *Config) {
 		config.Server.Host = host
 	}
 
+	if enabled := os.Getenv("TLS_ENABLED"); enabled != "" {
+		if b, err := strconv.ParseBool(enabled); err == nil {
+			config.Server.TLS.Enabled = b
+		}
+	}
+
+	if certFile := os.Getenv("TLS_CERT_FILE"); certFile != "" {
+		config.Server.TLS.CertFile = certFile
+	}
+
+	if keyFile := os.Getenv("TLS_KEY_FILE"); keyFile != "" {
+		config.Server.TLS.KeyFile = keyFile
+	}
+
+	if hostnames := os.Getenv("TLS_HOSTNAMES"); hostnames != "" {
+		names := strings.Split(hostnames, ",")
+		for i := range names {
+			names[i] = strings.TrimSpace(names[i])
+		}
+		config.Server.TLS.Hostnames = names
+	}
+
+	if certOut := os.Getenv("TLS_GENERATED_CERT_FILE"); certOut != "" {
+		config.Server.TLS.GeneratedCertFile = certOut
+	}
+
+	if clientCAFile := os.Getenv("TLS_CLIENT_CA_FILE"); clientCAFile != "" {
+		config.Server.TLS.ClientCAFile = clientCAFile
+	}
+
+	if httpPort := os.Getenv("TLS_HTTP_PORT"); httpPort != "" {
+		if p, err := strconv.Atoi(httpPort); err == nil && p >= 0 {
+			config.Server.TLS.HTTPPort = p
+		}
+	}
+
+	if adminHost := os.Getenv("ADMIN_HOST"); adminHost != "" {
+		config.Admin.Host = adminHost
+	}
+
+	if adminPort := os.Getenv("ADMIN_PORT"); adminPort != "" {
+		if p, err := strconv.Atoi(adminPort); err == nil && p >= 0 {
+			config.Admin.Port = p
+		}
+	}
+
+	if requireAuth := os.Getenv("ADMIN_REQUIRE_AUTH"); requireAuth != "" {
+		if b, err := strconv.ParseBool(requireAuth); err == nil {
+			config.Admin.RequireAuth = b
+		}
+	}
+
+	if apiKeys := os.Getenv("ADMIN_API_KEYS"); apiKeys != "" {
+		keys := strings.Split(apiKeys, ",")
+		for i := range keys {
+			keys[i] = strings.TrimSpace(keys[i])
+		}
+		config.Admin.APIKeys = keys
+	}
+
+	if fetchHosts := os.Getenv("REGISTRATION_FETCH_HOSTS"); fetchHosts != "" {
+		hosts := strings.Split(fetchHosts, ",")
+		for i := range hosts {
+			hosts[i] = strings.TrimSpace(hosts[i])
+		}
+		config.Registration.FetchHosts = hosts
+	}
+
+	if clientIDs := os.Getenv("ADMIN_CLIENT_IDS"); clientIDs != "" {
+		ids := strings.Split(clientIDs, ",")
+		for i := range ids {
+			ids[i] = strings.TrimSpace(ids[i])
+		}
+		config.Admin.ClientIDs = ids
+	}
+
 	if issuer := os.Getenv("JWT_ISSUER"); issuer != "" {
 		config.JWT.Issuer = issuer
 	}
@@ -97,10 +521,70 @@ func loadFromEnv(config *Config) {
 		config.JWT.Audience = audience
 	}
 
+	if profile := os.Getenv("JWT_PROFILE"); profile != "" {
+		config.JWT.Profile = strings.ToLower(profile)
+	}
+
+	if maxAge := os.Getenv("JWKS_MAX_AGE"); maxAge != "" {
+		if m, err := strconv.Atoi(maxAge); err == nil && m >= 0 {
+			config.JWKS.MaxAge = m
+		}
+	}
+
+	if noStore := os.Getenv("JWKS_NO_STORE"); noStore != "" {
+		if b, err := strconv.ParseBool(noStore); err == nil {
+			config.JWKS.NoStore = b
+		}
+	}
+
 	if logLevel := os.Getenv("LOG_LEVEL"); logLevel != "" {
 		config.LogLevel = strings.ToLower(logLevel)
 	}
 
+	if capacity := os.Getenv("RECORDING_CAPACITY"); capacity != "" {
+		if c, err := strconv.Atoi(capacity); err == nil && c >= 0 {
+			config.Recording.Capacity = c
+		}
+	}
+
+	if maxBody := os.Getenv("RECORDING_MAX_BODY_BYTES"); maxBody != "" {
+		if m, err := strconv.Atoi(maxBody); err == nil && m >= 0 {
+			config.Recording.MaxBodyBytes = m
+		}
+	}
+
+	if logFormat := os.Getenv("LOG_FORMAT"); logFormat != "" {
+		config.LogFormat = strings.ToLower(logFormat)
+	}
+
+	if logOutput := os.Getenv("LOG_OUTPUT"); logOutput != "" {
+		config.LogOutput = logOutput
+	}
+
+	if requireAuth := os.Getenv("INTROSPECTION_REQUIRE_AUTH"); requireAuth != "" {
+		if b, err := strconv.ParseBool(requireAuth); err == nil {
+			config.Introspection.RequireAuth = b
+		}
+	}
+
+	if expiresIn := os.Getenv("DEVICE_CODE_EXPIRES_IN"); expiresIn != "" {
+		if e, err := strconv.Atoi(expiresIn); err == nil && e > 0 {
+			config.DeviceFlow.ExpiresIn = e
+		}
+	}
+
+	if interval := os.Getenv("DEVICE_CODE_INTERVAL"); interval != "" {
+		if i, err := strconv.Atoi(interval); err == nil && i >= 0 {
+			config.DeviceFlow.Interval = i
+		}
+	}
+
+	if requireNonce := os.Getenv("DPOP_REQUIRE_NONCE"); requireNonce != "" {
+		if b, err := strconv.ParseBool(requireNonce); err == nil {
+			config.DPoP.RequireNonce = b
+		}
+	}
+
 	if keyIDs := os.Getenv("KEY_IDS"); keyIDs != "" {
 		ids := strings.Split(keyIDs, ",")
 		for i := range ids {
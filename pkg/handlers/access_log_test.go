@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/shogotsuneto/jwks-mock-api/pkg/config"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/logger"
+)
+
+func TestAccessLogRequestID(t *testing.T) {
+	h := newTestHandler(t, &config.Config{JWT: config.JWTConfig{Issuer: testIssuer, Audience: "dev-api", Profile: config.ProfileJWT}})
+	handler := h.AccessLog(http.HandlerFunc(h.GenerateToken))
+
+	var buf bytes.Buffer
+	logger.Setup(logger.Options{Level: "info", Format: logger.FormatJSON, Output: &buf})
+	t.Cleanup(func() {
+		logger.SetOutput(os.Stderr)
+		logger.Init("info")
+	})
+
+	tests := []struct {
+		name      string
+		requestID string
+		wantSame  bool
+	}{
+		{"propagated", "req-123", true},
+		{"generated", "", false},
+		{"invalid replaced", "bad id\n", false},
+		{"too long replaced", strings.Repeat("a", maxRequestIDLength+1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest("POST", "/generate-token", strings.NewReader(`{"claims":{"sub":"alice"}}`))
+			if tt.requestID != "" {
+				req.Header.Set("X-Request-ID", tt.requestID)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("GenerateToken() status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+			}
+			id := rec.Header().Get("X-Request-ID")
+			if id == "" {
+				t.Fatal("response has no X-Request-ID header")
+			}
+			if (id == tt.requestID) != tt.wantSame {
+				t.Errorf("X-Request-ID = %q, supplied %q", id, tt.requestID)
+			}
+
+			var entry map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("access log is not a JSON line: %v: %s", err, buf.String())
+			}
+			want := map[string]interface{}{
+				"level":      "INFO",
+				"request_id": id,
+				"method":     "POST",
+				"path":       "/generate-token",
+				"status":     float64(http.StatusOK),
+				"kid":        "test-key",
+				"sub":        "alice",
+			}
+			for key, value := range want {
+				if entry[key] != value {
+					t.Errorf("access log %s = %v, want %v", key, entry[key], value)
+				}
+			}
+			if _, ok := entry["duration_ms"].(float64); !ok {
+				t.Errorf("access log duration_ms = %v, want a number", entry["duration_ms"])
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// defaultAdminScope is the scope admin bearer tokens must grant unless another is configured
+const defaultAdminScope = "admin"
+
+// AdminAuthErrorResponse represents a rejected admin request
+type AdminAuthErrorResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// AdminAuth protects the admin endpoints when admin authentication is required. Callers
+// present a static API key, basic auth credentials of an admin user, or a bearer token
+// issued by this service to an admin client that grants the admin scope.
+func (h *Handler) AdminAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !h.config.Admin.RequireAuth {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if status, message := h.authenticateAdmin(r); status != http.StatusOK {
+			writeAdminAuthError(w, status, message)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authenticateAdmin checks the admin credentials of a request. It returns 401 when the
+// credentials are missing or wrong and 403 when a valid token lacks the admin scope or
+// was not issued to an admin client.
+func (h *Handler) authenticateAdmin(r *http.Request) (int, string) {
+	if apiKey := r.Header.Get("X-API-Key"); apiKey != "" {
+		for _, key := range h.config.Admin.APIKeys {
+			if key != "" && secureCompare(apiKey, key) {
+				return http.StatusOK, ""
+			}
+		}
+		return http.StatusUnauthorized, "Invalid API key"
+	}
+
+	if username, password, ok := r.BasicAuth(); ok {
+		for _, user := range h.config.Admin.Users {
+			if user.Password != "" && secureCompare(username, user.Username) && secureCompare(password, user.Password) {
+				return http.StatusOK, ""
+			}
+		}
+		return http.StatusUnauthorized, "Invalid username or password"
+	}
+
+	if token := bearerToken(r); token != "" {
+		claims, err := h.validateToken(token)
+		if err != nil {
+			return http.StatusUnauthorized, "Invalid bearer token"
+		}
+
+		scope := h.adminScope()
+		if !containsString(tokenScopes(claims), scope) {
+			return http.StatusForbidden, "The bearer token does not grant the " + scope + " scope"
+		}
+
+		// Anyone can obtain tokens from the public grants, so the token must name an admin client
+		clientID, _ := claims["client_id"].(string)
+		if clientID == "" {
+			clientID, _ = claims["azp"].(string)
+		}
+		if clientID == "" || !containsString(h.config.Admin.ClientIDs, clientID) {
+			return http.StatusForbidden, "The bearer token was not issued to an admin client"
+		}
+		return http.StatusOK, ""
+	}
+
+	return http.StatusUnauthorized, "Admin authentication is required"
+}
+
+// adminScope returns the scope admin bearer tokens must grant
+func (h *Handler) adminScope() string {
+	if h.config.Admin.Scope != "" {
+		return h.config.Admin.Scope
+	}
+	return defaultAdminScope
+}
+
+// withoutAdminScope removes the admin scope from a space-delimited scope string, so the
+// public grants never issue it. Admin tokens are only minted through /generate-token.
+func (h *Handler) withoutAdminScope(scope string) string {
+	adminScope := h.adminScope()
+	granted := []string{}
+	for _, s := range strings.Fields(scope) {
+		if s != adminScope {
+			granted = append(granted, s)
+		}
+	}
+	return strings.Join(granted, " ")
+}
+
+// writeAdminAuthError writes a rejected admin request, challenging the caller to authenticate on 401
+func writeAdminAuthError(w http.ResponseWriter, status int, message string) {
+	if status == http.StatusUnauthorized {
+		w.Header().Add("WWW-Authenticate", `Basic realm="admin"`)
+		w.Header().Add("WWW-Authenticate", `Bearer realm="admin"`)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(AdminAuthErrorResponse{
+		Success: false,
+		Message: message,
+	})
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/config"
+)
+
+func TestAdminAuth(t *testing.T) {
+	h := newTestHandler(t, &config.Config{
+		JWT: config.JWTConfig{Issuer: testIssuer, Audience: "dev-api"},
+		Admin: config.AdminConfig{
+			RequireAuth: true,
+			APIKeys:     []string{"admin-key"},
+			Users:       []config.AdminUserConfig{{Username: "admin", Password: "admin-password"}},
+			Scope:       "admin",
+			ClientIDs:   []string{"ops-cli"},
+		},
+	})
+
+	adminToken, _, err := h.issueToken(jwt.MapClaims{"sub": "ops", "client_id": "ops-cli", "scope": "openid admin"}, 300)
+	if err != nil {
+		t.Fatalf("failed to issue token: %v", err)
+	}
+	azpToken, _, err := h.issueToken(jwt.MapClaims{"sub": "ops", "azp": "ops-cli", "scope": "admin"}, 300)
+	if err != nil {
+		t.Fatalf("failed to issue token: %v", err)
+	}
+	otherClientToken, _, err := h.issueToken(jwt.MapClaims{"sub": "ops", "client_id": "web-app", "scope": "admin"}, 300)
+	if err != nil {
+		t.Fatalf("failed to issue token: %v", err)
+	}
+	noClientToken, _, err := h.issueToken(jwt.MapClaims{"sub": "ops", "scope": "admin"}, 300)
+	if err != nil {
+		t.Fatalf("failed to issue token: %v", err)
+	}
+	userToken, _, err := h.issueToken(jwt.MapClaims{"sub": "user", "scope": "openid"}, 300)
+	if err != nil {
+		t.Fatalf("failed to issue token: %v", err)
+	}
+
+	protected := h.AdminAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name       string
+		setup      func(r *http.Request)
+		wantStatus int
+	}{
+		{"no credentials", func(r *http.Request) {}, http.StatusUnauthorized},
+		{"valid API key", func(r *http.Request) { r.Header.Set("X-API-Key", "admin-key") }, http.StatusOK},
+		{"invalid API key", func(r *http.Request) { r.Header.Set("X-API-Key", "wrong") }, http.StatusUnauthorized},
+		{"valid basic auth", func(r *http.Request) { r.SetBasicAuth("admin", "admin-password") }, http.StatusOK},
+		{"invalid basic auth", func(r *http.Request) { r.SetBasicAuth("admin", "wrong") }, http.StatusUnauthorized},
+		{"admin bearer token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+adminToken) }, http.StatusOK},
+		{"admin bearer token by azp", func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+azpToken) }, http.StatusOK},
+		{"admin scope for another client", func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+otherClientToken) }, http.StatusForbidden},
+		{"admin scope without a client", func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+noClientToken) }, http.StatusForbidden},
+		{"bearer token without admin scope", func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+userToken) }, http.StatusForbidden},
+		{"invalid bearer token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer not-a-jwt") }, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/generate-token", nil)
+			tt.setup(req)
+			rec := httptest.NewRecorder()
+			protected.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("AdminAuth() status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if rec.Code == http.StatusUnauthorized && len(rec.Header().Values("WWW-Authenticate")) == 0 {
+				t.Error("AdminAuth() 401 response has no WWW-Authenticate challenge")
+			}
+		})
+	}
+
+	// Without require_auth every request passes through
+	h.config.Admin.RequireAuth = false
+	rec := httptest.NewRecorder()
+	protected.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/generate-token", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("AdminAuth() without require_auth status = %d, want 200", rec.Code)
+	}
+}
+
+func TestWithoutAdminScope(t *testing.T) {
+	h := &Handler{config: &config.Config{}}
+	tests := []struct {
+		scope string
+		want  string
+	}{
+		{"", ""},
+		{"admin", ""},
+		{"openid admin profile", "openid profile"},
+		{"openid admin:users", "openid admin:users"},
+	}
+	for _, tt := range tests {
+		if got := h.withoutAdminScope(tt.scope); got != tt.want {
+			t.Errorf("withoutAdminScope(%q) = %q, want %q", tt.scope, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,419 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/shogotsuneto/jwks-mock-api/internal/authz"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/config"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/logger"
+)
+
+// parExpiresIn is the lifetime in seconds of a pushed authorization request URI
+const parExpiresIn = 60
+
+// authorizationCodeLifetime is how long an authorization code can be redeemed
+const authorizationCodeLifetime = 60 * time.Second
+
+// maxRequestObjectSize limits request objects fetched by reference
+const maxRequestObjectSize = 64 * 1024
+
+// requestObjectRegisteredClaims are JWT claims of a request object that are not authorization parameters
+var requestObjectRegisteredClaims = map[string]bool{
+	"iss": true, "aud": true, "exp": true, "iat": true, "nbf": true, "jti": true,
+}
+
+// PushedAuthorizationResponse represents a pushed authorization response (RFC 9126 section 2.2)
+type PushedAuthorizationResponse struct {
+	RequestURI string `json:"request_uri"`
+	ExpiresIn  int    `json:"expires_in"`
+}
+
+// PushedAuthorizationRequest implements the pushed authorization request endpoint (RFC 9126)
+func (h *Handler) PushedAuthorizationRequest(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "Malformed form body")
+		return
+	}
+
+	client, authErr := h.authenticateClient(r)
+	if authErr != nil {
+		writeClientAuthError(w, authErr)
+		return
+	}
+
+	if r.PostForm.Get("request_uri") != "" {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "The request_uri parameter is not allowed at the PAR endpoint")
+		return
+	}
+
+	params := url.Values{}
+	for name, values := range r.PostForm {
+		switch name {
+		case "client_secret", "client_assertion", "client_assertion_type":
+			continue
+		}
+		params[name] = values
+	}
+
+	signed := false
+	if requestObject := params.Get("request"); requestObject != "" {
+		objectParams, err := h.requestObjectParams(r.Context(), requestObject, client)
+		if err != nil {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_request_object", err.Error())
+			return
+		}
+		params = objectParams
+		signed = true
+	}
+
+	if client.RequireSignedRequestObject && !signed {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "This client must use a signed request object")
+		return
+	}
+	if params.Get("response_type") == "" {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "The response_type parameter is required")
+		return
+	}
+	if _, err := resolveRedirectURI(client, params.Get("redirect_uri")); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	pushed, err := h.authz.Push(client.ClientID, params, signed, parExpiresIn*time.Second)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Error storing pushed authorization request", "error", err)
+		writeOAuthError(w, http.StatusInternalServerError, "server_error", "Failed to store authorization request")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(PushedAuthorizationResponse{
+		RequestURI: pushed.RequestURI,
+		ExpiresIn:  parExpiresIn,
+	})
+}
+
+// Authorize implements the authorization endpoint for the authorization code flow. Requests may
+// be sent as plain parameters, as a signed request object (RFC 9101) or by reference to a pushed
+// authorization request (RFC 9126). The user is approved automatically.
+func (h *Handler) Authorize(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "Malformed request")
+		return
+	}
+
+	params := r.Form
+	clientID := params.Get("client_id")
+	if clientID == "" {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "The client_id parameter is required")
+		return
+	}
+	client, ok := h.clients.Get(clientID)
+	if !ok {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "Unknown client: "+clientID)
+		return
+	}
+
+	pushed := false
+	signed := false
+	requestURI := params.Get("request_uri")
+	requestObject := params.Get("request")
+	switch {
+	case requestURI != "" && requestObject != "":
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "The request and request_uri parameters cannot both be used")
+		return
+	case strings.HasPrefix(requestURI, authz.RequestURIPrefix):
+		request, err := h.authz.TakeRequest(requestURI, client.ClientID)
+		if err != nil {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_request_uri", err.Error())
+			return
+		}
+		params = request.Params
+		pushed = true
+		signed = request.Signed
+	case requestURI != "":
+		// Only URIs the client registered are fetched; dynamically registered clients are
+		// limited to the configured fetch hosts
+		if !containsString(client.RequestURIs, requestURI) {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_request_uri", "The request_uri is not registered for the client")
+			return
+		}
+		fetched, err := fetchRequestObject(r.Context(), requestURI)
+		if err != nil {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_request_uri", err.Error())
+			return
+		}
+		requestObject = fetched
+		fallthrough
+	case requestObject != "":
+		objectParams, err := h.requestObjectParams(r.Context(), requestObject, client)
+		if err != nil {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_request_object", err.Error())
+			return
+		}
+		params = objectParams
+		signed = true
+	}
+
+	if client.RequirePushedAuthorizationRequests && !pushed {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "This client must use pushed authorization requests")
+		return
+	}
+	if client.RequireSignedRequestObject && !signed {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "This client must use a signed request object")
+		return
+	}
+
+	// Without a valid redirect URI errors cannot be returned to the client
+	redirectURI, err := resolveRedirectURI(client, params.Get("redirect_uri"))
+	if err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	state := params.Get("state")
+
+	if params.Get("response_type") != "code" {
+		h.redirectAuthorizationError(w, r, redirectURI, state, "unsupported_response_type", "Only the code response type is supported")
+		return
+	}
+	if !clientAllowsGrant(client, GrantTypeAuthorizationCode) {
+		h.redirectAuthorizationError(w, r, redirectURI, state, "unauthorized_client", "The client is not registered for the authorization_code grant")
+		return
+	}
+
+	codeChallenge := params.Get("code_challenge")
+	codeChallengeMethod := params.Get("code_challenge_method")
+	if codeChallenge != "" {
+		if codeChallengeMethod == "" {
+			codeChallengeMethod = "plain"
+		}
+		if codeChallengeMethod != "S256" && codeChallengeMethod != "plain" {
+			h.redirectAuthorizationError(w, r, redirectURI, state, "invalid_request", "Unsupported code_challenge_method: "+codeChallengeMethod)
+			return
+		}
+	}
+
+	sub := params.Get("login_hint")
+	if sub == "" {
+		sub = h.defaultSubject()
+	}
+
+	code, err := h.authz.IssueCode(authz.Code{
+		ClientID:            client.ClientID,
+		RedirectURI:         params.Get("redirect_uri"),
+		Scope:               params.Get("scope"),
+		Nonce:               params.Get("nonce"),
+		Subject:             sub,
+		CodeChallenge:       codeChallenge,
+		CodeChallengeMethod: codeChallengeMethod,
+	}, authorizationCodeLifetime)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Error issuing authorization code", "error", err)
+		h.redirectAuthorizationError(w, r, redirectURI, state, "server_error", "Failed to issue authorization code")
+		return
+	}
+
+	response := url.Values{}
+	response.Set("code", code)
+	if state != "" {
+		response.Set("state", state)
+	}
+	response.Set("iss", h.config.JWT.Issuer)
+	http.Redirect(w, r, appendQuery(redirectURI, response), http.StatusFound)
+}
+
+// redirectAuthorizationError returns an authorization error to the client's redirect URI (RFC 6749 section 4.1.2.1)
+func (h *Handler) redirectAuthorizationError(w http.ResponseWriter, r *http.Request, redirectURI, state, errorCode, description string) {
+	response := url.Values{}
+	response.Set("error", errorCode)
+	response.Set("error_description", description)
+	if state != "" {
+		response.Set("state", state)
+	}
+	response.Set("iss", h.config.JWT.Issuer)
+	http.Redirect(w, r, appendQuery(redirectURI, response), http.StatusFound)
+}
+
+// appendQuery adds query parameters to a URL that may already have a query
+func appendQuery(rawURL string, params url.Values) string {
+	separator := "?"
+	if strings.Contains(rawURL, "?") {
+		separator = "&"
+	}
+	return rawURL + separator + params.Encode()
+}
+
+// resolveRedirectURI checks a requested redirect URI against the client's registrations.
+// When the client registered exactly one, the parameter may be omitted.
+func resolveRedirectURI(client *config.ClientConfig, redirectURI string) (string, error) {
+	if redirectURI == "" {
+		if len(client.RedirectURIs) == 1 {
+			return client.RedirectURIs[0], nil
+		}
+		return "", fmt.Errorf("The redirect_uri parameter is required")
+	}
+	for _, registered := range client.RedirectURIs {
+		if registered == redirectURI {
+			return redirectURI, nil
+		}
+	}
+	return "", fmt.Errorf("The redirect_uri is not registered for this client")
+}
+
+// requestObjectParams verifies a request object (RFC 9101) signed by the client and
+// returns its claims as authorization request parameters
+func (h *Handler) requestObjectParams(ctx context.Context, requestObject string, client *config.ClientConfig) (url.Values, error) {
+	claims, err := parseClientJWT(ctx, requestObject, client)
+	if err != nil {
+		return nil, fmt.Errorf("Request object verification failed: %v", err)
+	}
+
+	if iss, _ := claims["iss"].(string); iss != client.ClientID {
+		return nil, fmt.Errorf("The request object issuer must be the client_id")
+	}
+	aud, err := claims.GetAudience()
+	if err != nil || !containsString(aud, h.config.JWT.Issuer) {
+		return nil, fmt.Errorf("The request object audience must be the issuer %s", h.config.JWT.Issuer)
+	}
+	if clientID, ok := claims["client_id"].(string); ok && clientID != client.ClientID {
+		return nil, fmt.Errorf("The request object client_id does not match the client")
+	}
+
+	params := url.Values{}
+	for name, value := range claims {
+		if requestObjectRegisteredClaims[name] {
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			params.Set(name, v)
+		default:
+			// Structured parameters such as claims are passed on as JSON
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid request object parameter %s", name)
+			}
+			params.Set(name, string(encoded))
+		}
+	}
+	params.Set("client_id", client.ClientID)
+	return params, nil
+}
+
+// fetchRequestObject retrieves a request object passed by reference (RFC 9101 section 5.2)
+func fetchRequestObject(ctx context.Context, requestURI string) (string, error) {
+	parsed, err := url.Parse(requestURI)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+		return "", fmt.Errorf("The request_uri must be an http(s) URL or a pushed request URI")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURI, nil)
+	if err != nil {
+		return "", fmt.Errorf("Invalid request_uri: %v", err)
+	}
+	req.Header.Set("Accept", "application/oauth-authz-req+jwt")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("Failed to fetch request_uri: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Fetching request_uri returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRequestObjectSize))
+	if err != nil {
+		return "", fmt.Errorf("Failed to read request_uri: %v", err)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+// authorizationCodeGrant implements the authorization code grant (RFC 6749 section 4.1.3)
+func (h *Handler) authorizationCodeGrant(w http.ResponseWriter, r *http.Request) {
+	client, authErr := h.authenticateClient(r)
+	if authErr != nil {
+		writeClientAuthError(w, authErr)
+		return
+	}
+
+	if !clientAllowsGrant(client, GrantTypeAuthorizationCode) {
+		writeOAuthError(w, http.StatusBadRequest, "unauthorized_client", "The client is not registered for the authorization_code grant")
+		return
+	}
+
+	codeValue := r.PostFormValue("code")
+	if codeValue == "" {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "The code parameter is required")
+		return
+	}
+
+	code, err := h.authz.RedeemCode(codeValue)
+	if err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", err.Error())
+		return
+	}
+	if code.ClientID != client.ClientID {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "The code was issued to a different client")
+		return
+	}
+	if code.RedirectURI != "" && code.RedirectURI != r.PostFormValue("redirect_uri") {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "The redirect_uri does not match the authorization request")
+		return
+	}
+	if err := verifyCodeVerifier(code, r.PostFormValue("code_verifier")); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", err.Error())
+		return
+	}
+
+	user := h.config.FindUser(code.Subject)
+	if user == nil {
+		user = &config.UserConfig{Sub: code.Subject}
+	}
+	h.writeUserTokenResponse(w, r, user, client.ClientID, code.Scope, code.Nonce)
+}
+
+// verifyCodeVerifier checks a PKCE code verifier against the stored challenge (RFC 7636 section 4.6)
+func verifyCodeVerifier(code *authz.Code, verifier string) error {
+	if code.CodeChallenge == "" {
+		return nil
+	}
+	if verifier == "" {
+		return fmt.Errorf("The code_verifier parameter is required")
+	}
+
+	expected := verifier
+	if code.CodeChallengeMethod == "S256" {
+		sum := sha256.Sum256([]byte(verifier))
+		expected = base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+	if !secureCompare(expected, code.CodeChallenge) {
+		return fmt.Errorf("The code_verifier does not match the code_challenge")
+	}
+	return nil
+}
+
+// containsString reports whether values contains want
+func containsString(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
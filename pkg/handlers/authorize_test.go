@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/shogotsuneto/jwks-mock-api/internal/authz"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/config"
+)
+
+func TestVerifyCodeVerifier(t *testing.T) {
+	// Example from RFC 7636 appendix B
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	s256 := &authz.Code{CodeChallenge: "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", CodeChallengeMethod: "S256"}
+	plain := &authz.Code{CodeChallenge: verifier, CodeChallengeMethod: "plain"}
+
+	tests := []struct {
+		name     string
+		code     *authz.Code
+		verifier string
+		wantErr  bool
+	}{
+		{"S256 match", s256, verifier, false},
+		{"S256 mismatch", s256, "wrong-verifier", true},
+		{"S256 missing verifier", s256, "", true},
+		{"plain match", plain, verifier, false},
+		{"plain mismatch", plain, "wrong-verifier", true},
+		{"no challenge", &authz.Code{}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyCodeVerifier(tt.code, tt.verifier)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("verifyCodeVerifier() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthorizeRequestURIRegistration(t *testing.T) {
+	fetched := map[string]int{}
+	requestObjects := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched[r.URL.Path]++
+		w.Write([]byte("not-a-request-object"))
+	}))
+	defer requestObjects.Close()
+
+	registered := requestObjects.URL + "/registered.jwt"
+	h := newClientAuthHandler(t, config.ClientConfig{
+		ClientID:     "web-app",
+		RedirectURIs: []string{"http://localhost:8080/callback"},
+		RequestURIs:  []string{registered},
+	})
+
+	tests := []struct {
+		name       string
+		requestURI string
+		wantError  string
+		wantPath   string
+	}{
+		{"registered", registered, "invalid_request_object", "/registered.jwt"},
+		{"unregistered", requestObjects.URL + "/other.jwt", "invalid_request_uri", ""},
+		{"internal host", "http://169.254.169.254/latest/meta-data", "invalid_request_uri", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clear(fetched)
+			req := httptest.NewRequest("GET", "/authorize?"+url.Values{
+				"client_id":   {"web-app"},
+				"request_uri": {tt.requestURI},
+			}.Encode(), nil)
+			rec := httptest.NewRecorder()
+			h.Authorize(rec, req)
+
+			if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), tt.wantError) {
+				t.Errorf("Authorize() = %d %s, want 400 %s", rec.Code, rec.Body.String(), tt.wantError)
+			}
+			if tt.wantPath == "" && len(fetched) != 0 {
+				t.Errorf("Authorize() fetched %v, want no fetch", fetched)
+			}
+			if tt.wantPath != "" && fetched[tt.wantPath] != 1 {
+				t.Errorf("Authorize() fetched %v, want %s once", fetched, tt.wantPath)
+			}
+		})
+	}
+}
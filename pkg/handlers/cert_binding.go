@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"strings"
+)
+
+// certificateConfirmation returns the x5t#S256 confirmation value (RFC 8705 section 3.1) for a
+// PEM-encoded client certificate or a SHA-256 certificate thumbprint, base64url or hex
+func certificateConfirmation(certificatePEM, thumbprint string) (string, error) {
+	if certificatePEM != "" && thumbprint != "" {
+		return "", fmt.Errorf("only one of clientCertificate and certificateThumbprint can be set")
+	}
+
+	if certificatePEM != "" {
+		block, _ := pem.Decode([]byte(certificatePEM))
+		if block == nil || block.Type != "CERTIFICATE" {
+			return "", fmt.Errorf("clientCertificate must be a PEM-encoded certificate")
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return "", fmt.Errorf("invalid clientCertificate: %v", err)
+		}
+		return certificateThumbprint(cert), nil
+	}
+
+	if sum, err := base64.RawURLEncoding.DecodeString(thumbprint); err == nil && len(sum) == sha256.Size {
+		return thumbprint, nil
+	}
+	if sum, err := hex.DecodeString(strings.ReplaceAll(thumbprint, ":", "")); err == nil && len(sum) == sha256.Size {
+		return base64.RawURLEncoding.EncodeToString(sum), nil
+	}
+	return "", fmt.Errorf("certificateThumbprint must be a base64url or hex SHA-256 certificate thumbprint")
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCertificateConfirmation(t *testing.T) {
+	cert := selfSignedCertificate(t, "bound-client")
+	certPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+	sum := sha256.Sum256(cert.Raw)
+	want := certificateThumbprint(cert)
+
+	tests := []struct {
+		name       string
+		pem        string
+		thumbprint string
+		wantErr    bool
+	}{
+		{"PEM certificate", certPEM, "", false},
+		{"base64url thumbprint", "", want, false},
+		{"hex thumbprint", "", hex.EncodeToString(sum[:]), false},
+		{"colon-separated hex thumbprint", "", strings.ToUpper(colonHex(sum[:])), false},
+		{"both set", certPEM, want, true},
+		{"invalid PEM", "not a certificate", "", true},
+		{"short thumbprint", "", "abc", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := certificateConfirmation(tt.pem, tt.thumbprint)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("certificateConfirmation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got != want {
+				t.Errorf("certificateConfirmation() = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+// colonHex formats bytes as colon-separated hex, as shown by openssl x509 -fingerprint
+func colonHex(data []byte) string {
+	parts := make([]string, len(data))
+	for i, b := range data {
+		parts[i] = hex.EncodeToString([]byte{b})
+	}
+	return strings.Join(parts, ":")
+}
+
+func TestIntrospectCertificateBoundToken(t *testing.T) {
+	h := newClientAuthHandler(t)
+	cert := selfSignedCertificate(t, "bound-client")
+
+	body, _ := json.Marshal(TokenRequest{
+		Claims:                map[string]interface{}{"sub": "bound-user"},
+		CertificateThumbprint: certificateThumbprint(cert),
+	})
+	rec := httptest.NewRecorder()
+	h.GenerateToken(rec, httptest.NewRequest(http.MethodPost, "/generate-token", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GenerateToken() status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var tokenResp TokenResponse
+	if err := json.NewDecoder(rec.Body).Decode(&tokenResp); err != nil {
+		t.Fatalf("failed to decode token response: %v", err)
+	}
+
+	form := url.Values{"token": {tokenResp.Token}}
+	req := httptest.NewRequest(http.MethodPost, "/introspect", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	h.Introspect(rec, req)
+
+	var introspection map[string]interface{}
+	if err := json.NewDecoder(rec.Body).Decode(&introspection); err != nil {
+		t.Fatalf("failed to decode introspection response: %v", err)
+	}
+	cnf, _ := introspection["cnf"].(map[string]interface{})
+	if cnf["x5t#S256"] != certificateThumbprint(cert) {
+		t.Errorf("Introspect() cnf = %v, want x5t#S256 %s", introspection["cnf"], certificateThumbprint(cert))
+	}
+	if introspection["token_type"] != "Bearer" {
+		t.Errorf("Introspect() token_type = %v, want Bearer", introspection["token_type"])
+	}
+}
@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/shogotsuneto/jwks-mock-api/internal/clients"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/config"
+)
+
+// clientSigningAlgs are the JWS algorithms accepted for client-signed JWTs
+var clientSigningAlgs = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// ClientAssertionTypeJWTBearer is the client_assertion_type of private_key_jwt (RFC 7523 section 2.2)
+const ClientAssertionTypeJWTBearer = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// tokenEndpointAuthMethods are the client authentication methods accepted by the token endpoint
+var tokenEndpointAuthMethods = []string{
+	clients.AuthMethodClientSecretBasic,
+	clients.AuthMethodClientSecretPost,
+	clients.AuthMethodPrivateKeyJWT,
+	clients.AuthMethodTLSClientAuth,
+	clients.AuthMethodNone,
+}
+
+// clientAuthError is returned when a client fails to authenticate
+type clientAuthError struct {
+	description string
+	basic       bool // the client attempted HTTP basic authentication
+}
+
+func (e *clientAuthError) Error() string {
+	return e.description
+}
+
+// writeClientAuthError writes an invalid_client error, challenging basic auth when it was used
+func writeClientAuthError(w http.ResponseWriter, err *clientAuthError) {
+	if err.basic {
+		w.Header().Set("WWW-Authenticate", `Basic realm="token"`)
+	}
+	writeOAuthError(w, http.StatusUnauthorized, "invalid_client", err.description)
+}
+
+// authenticateClient authenticates the client making a request with client_secret_basic,
+// client_secret_post, private_key_jwt, tls_client_auth, or none for public clients.
+// The form must already be parsed.
+func (h *Handler) authenticateClient(r *http.Request) (*config.ClientConfig, *clientAuthError) {
+	if assertionType := r.PostFormValue("client_assertion_type"); assertionType != "" {
+		return h.authenticateClientAssertion(r, assertionType)
+	}
+
+	clientID, clientSecret, basic := r.BasicAuth()
+	method := clients.AuthMethodClientSecretBasic
+	if !basic {
+		clientID = r.PostFormValue("client_id")
+		clientSecret = r.PostFormValue("client_secret")
+		method = clients.AuthMethodClientSecretPost
+	}
+	if clientID == "" {
+		return nil, &clientAuthError{description: "Client authentication is required", basic: basic}
+	}
+
+	client, ok := h.clients.Get(clientID)
+	if !ok {
+		return nil, &clientAuthError{description: "Unknown client: " + clientID, basic: basic}
+	}
+
+	switch {
+	case basic || clientSecret != "":
+		if client.ClientSecret == "" || !secureCompare(clientSecret, client.ClientSecret) {
+			return nil, &clientAuthError{description: "Invalid client credentials", basic: basic}
+		}
+	case r.TLS != nil && len(r.TLS.PeerCertificates) > 0 && hasTLSClientAuth(client):
+		if !certificateMatchesClient(r.TLS.PeerCertificates[0], len(r.TLS.VerifiedChains) > 0, client) {
+			return nil, &clientAuthError{description: "The client certificate does not match the client"}
+		}
+		method = clients.AuthMethodTLSClientAuth
+	case isPublicClient(client):
+		method = clients.AuthMethodNone
+	default:
+		return nil, &clientAuthError{description: "Client authentication is required"}
+	}
+
+	if !clientMethodAllowed(client, method) {
+		return nil, &clientAuthError{description: "The client must authenticate with " + client.TokenEndpointAuthMethod, basic: basic}
+	}
+	return client, nil
+}
+
+// authenticateClientAssertion authenticates a client with a signed JWT assertion (private_key_jwt,
+// RFC 7523 section 2.2 and OpenID Connect Core section 9)
+func (h *Handler) authenticateClientAssertion(r *http.Request, assertionType string) (*config.ClientConfig, *clientAuthError) {
+	if assertionType != ClientAssertionTypeJWTBearer {
+		return nil, &clientAuthError{description: "Unsupported client_assertion_type: " + assertionType}
+	}
+
+	assertion := r.PostFormValue("client_assertion")
+	clientID := assertionClientID(assertion)
+	if clientID == "" {
+		return nil, &clientAuthError{description: "The client_assertion must be a JWT whose sub is the client_id"}
+	}
+	if formClientID := r.PostFormValue("client_id"); formClientID != "" && formClientID != clientID {
+		return nil, &clientAuthError{description: "The client_id does not match the client_assertion"}
+	}
+
+	client, ok := h.clients.Get(clientID)
+	if !ok {
+		return nil, &clientAuthError{description: "Unknown client: " + clientID}
+	}
+
+	claims, err := parseClientJWT(r.Context(), assertion, client)
+	if err != nil {
+		return nil, &clientAuthError{description: "Client assertion verification failed: " + err.Error()}
+	}
+
+	if iss, _ := claims["iss"].(string); iss != clientID {
+		return nil, &clientAuthError{description: "The client_assertion iss must be the client_id"}
+	}
+	aud, err := claims.GetAudience()
+	if err != nil || !(containsString(aud, h.config.JWT.Issuer) ||
+		containsString(aud, h.endpointURL("/token")) || containsString(aud, h.endpointURL(r.URL.Path))) {
+		return nil, &clientAuthError{description: "The client_assertion aud must be the issuer or the endpoint URL"}
+	}
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return nil, &clientAuthError{description: "The client_assertion must have an exp claim"}
+	}
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return nil, &clientAuthError{description: "The client_assertion must have a jti claim"}
+	}
+	if !h.assertions.Use(clientID+" "+jti, exp.Time) {
+		return nil, &clientAuthError{description: "The client_assertion has already been used"}
+	}
+
+	if !clientMethodAllowed(client, clients.AuthMethodPrivateKeyJWT) {
+		return nil, &clientAuthError{description: "The client must authenticate with " + client.TokenEndpointAuthMethod}
+	}
+	return client, nil
+}
+
+// assertionClientID returns the unverified sub of a client assertion, which names the client
+func assertionClientID(assertion string) string {
+	if assertion == "" {
+		return ""
+	}
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(assertion, claims); err != nil {
+		return ""
+	}
+	sub, _ := claims["sub"].(string)
+	return sub
+}
+
+// isPublicClient reports whether a client may identify itself without credentials
+func isPublicClient(client *config.ClientConfig) bool {
+	return client.ClientSecret == "" && (client.TokenEndpointAuthMethod == "" || client.TokenEndpointAuthMethod == clients.AuthMethodNone)
+}
+
+// clientMethodAllowed reports whether a client may authenticate with a method. Clients without a
+// registered method accept any, and the two client secret methods are interchangeable.
+func clientMethodAllowed(client *config.ClientConfig, method string) bool {
+	registered := client.TokenEndpointAuthMethod
+	if registered == "" || registered == method {
+		return true
+	}
+	return clients.UsesClientSecret(registered) && clients.UsesClientSecret(method)
+}
+
+// hasTLSClientAuth reports whether a client has a certificate registered for tls_client_auth
+func hasTLSClientAuth(client *config.ClientConfig) bool {
+	return client.TLSClientAuthSubjectDN != "" || client.TLSClientCertThumbprint != ""
+}
+
+// certificateThumbprint returns the base64url SHA-256 thumbprint of a certificate (x5t#S256)
+func certificateThumbprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// certificateMatchesClient checks a client certificate against the client's registered subject
+// DN and thumbprint; every registered value must match. Anyone can issue a certificate with a
+// given subject, so the subject DN alone only identifies a certificate verified against the
+// configured client CAs (RFC 8705 section 2.1).
+func certificateMatchesClient(cert *x509.Certificate, verified bool, client *config.ClientConfig) bool {
+	if client.TLSClientAuthSubjectDN != "" && cert.Subject.String() != client.TLSClientAuthSubjectDN {
+		return false
+	}
+	if client.TLSClientAuthSubjectDN != "" && client.TLSClientCertThumbprint == "" && !verified {
+		return false
+	}
+	if thumbprint := client.TLSClientCertThumbprint; thumbprint != "" {
+		sum := sha256.Sum256(cert.Raw)
+		hexThumbprint := strings.ToLower(strings.ReplaceAll(thumbprint, ":", ""))
+		if thumbprint != certificateThumbprint(cert) && hexThumbprint != hex.EncodeToString(sum[:]) {
+			return false
+		}
+	}
+	return hasTLSClientAuth(client)
+}
+
+// clientKeySet returns the client's registered public keys, from the inline JWKS or its jwks_uri
+func clientKeySet(ctx context.Context, client *config.ClientConfig) (jwk.Set, error) {
+	if len(client.JWKS) > 0 {
+		data, err := json.Marshal(client.JWKS)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode client JWKS: %w", err)
+		}
+		set, err := jwk.Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse client JWKS: %w", err)
+		}
+		return set, nil
+	}
+
+	if client.JWKSURI != "" {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		set, err := jwk.Fetch(ctx, client.JWKSURI)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch client JWKS from %s: %w", client.JWKSURI, err)
+		}
+		return set, nil
+	}
+
+	return nil, fmt.Errorf("client %s has no registered keys", client.ClientID)
+}
+
+// parseClientJWT verifies a JWT signed by a client against the client's registered keys
+func parseClientJWT(ctx context.Context, tokenString string, client *config.ClientConfig) (jwt.MapClaims, error) {
+	set, err := clientKeySet(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		var key jwk.Key
+		var ok bool
+		if kid, _ := token.Header["kid"].(string); kid != "" {
+			key, ok = set.LookupKeyID(kid)
+		} else if set.Len() == 1 {
+			key, ok = set.Key(0)
+		}
+		if !ok {
+			return nil, fmt.Errorf("no matching client key for kid: %v", token.Header["kid"])
+		}
+
+		var raw interface{}
+		if err := key.Raw(&raw); err != nil {
+			return nil, fmt.Errorf("failed to extract client key: %w", err)
+		}
+		return raw, nil
+	}, jwt.WithValidMethods(clientSigningAlgs))
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, fmt.Errorf("invalid client JWT")
+	}
+	return claims, nil
+}
+
+// clientAllowsGrant reports whether a client may use a grant type. Clients without
+// registered grant types may use any of them.
+func clientAllowsGrant(client *config.ClientConfig, grantType string) bool {
+	if len(client.GrantTypes) == 0 {
+		return true
+	}
+	for _, allowed := range client.GrantTypes {
+		if allowed == grantType {
+			return true
+		}
+	}
+	return false
+}
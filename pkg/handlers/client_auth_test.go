@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/config"
+)
+
+// newClientAuthHandler creates a handler with the given clients
+func newClientAuthHandler(t *testing.T, clients ...config.ClientConfig) *Handler {
+	t.Helper()
+
+	return newTestHandler(t, &config.Config{
+		JWT:     config.JWTConfig{Issuer: testIssuer, Audience: "dev-api"},
+		Clients: clients,
+	})
+}
+
+// publicJWKS returns an inline JWKS holding the public half of key
+func publicJWKS(t *testing.T, key *rsa.PrivateKey, kid string) map[string]interface{} {
+	t.Helper()
+
+	publicKey, err := jwk.FromRaw(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to create JWK: %v", err)
+	}
+	publicKey.Set(jwk.KeyIDKey, kid)
+
+	data, err := json.Marshal(map[string]interface{}{"keys": []jwk.Key{publicKey}})
+	if err != nil {
+		t.Fatalf("failed to encode JWKS: %v", err)
+	}
+	var jwks map[string]interface{}
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		t.Fatalf("failed to decode JWKS: %v", err)
+	}
+	return jwks
+}
+
+// clientAssertion signs a private_key_jwt assertion, letting the caller adjust the claims
+func clientAssertion(t *testing.T, key *rsa.PrivateKey, clientID string, modify func(jwt.MapClaims)) string {
+	t.Helper()
+
+	claims := jwt.MapClaims{
+		"iss": clientID,
+		"sub": clientID,
+		"aud": testIssuer + "/token",
+		"jti": newRandomID(),
+		"exp": time.Now().Add(time.Minute).Unix(),
+	}
+	if modify != nil {
+		modify(claims)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "client-key"
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign assertion: %v", err)
+	}
+	return signed
+}
+
+// assertionRequest builds a token request authenticated with a client assertion
+func assertionRequest(assertion string) *http.Request {
+	form := url.Values{
+		"client_assertion_type": {ClientAssertionTypeJWTBearer},
+		"client_assertion":      {assertion},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.ParseForm()
+	return req
+}
+
+func TestPrivateKeyJWTAuthentication(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	h := newClientAuthHandler(t, config.ClientConfig{
+		ClientID:                "jwt-client",
+		TokenEndpointAuthMethod: "private_key_jwt",
+		JWKS:                    publicJWKS(t, key, "client-key"),
+	})
+
+	valid := clientAssertion(t, key, "jwt-client", nil)
+	tests := []struct {
+		name      string
+		assertion string
+		wantErr   bool
+	}{
+		{"valid assertion", valid, false},
+		{"replayed assertion", valid, true},
+		{"issuer audience", clientAssertion(t, key, "jwt-client", func(c jwt.MapClaims) { c["aud"] = testIssuer }), false},
+		{"wrong audience", clientAssertion(t, key, "jwt-client", func(c jwt.MapClaims) { c["aud"] = "https://elsewhere" }), true},
+		{"missing jti", clientAssertion(t, key, "jwt-client", func(c jwt.MapClaims) { delete(c, "jti") }), true},
+		{"missing exp", clientAssertion(t, key, "jwt-client", func(c jwt.MapClaims) { delete(c, "exp") }), true},
+		{"expired", clientAssertion(t, key, "jwt-client", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }), true},
+		{"issuer mismatch", clientAssertion(t, key, "jwt-client", func(c jwt.MapClaims) { c["iss"] = "someone-else" }), true},
+		{"unregistered key", clientAssertion(t, otherKey, "jwt-client", nil), true},
+		{"unknown client", clientAssertion(t, key, "unknown-client", nil), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, authErr := h.authenticateClient(assertionRequest(tt.assertion))
+			if (authErr != nil) != tt.wantErr {
+				t.Fatalf("authenticateClient() error = %v, wantErr %v", authErr, tt.wantErr)
+			}
+			if authErr == nil && client.ClientID != "jwt-client" {
+				t.Errorf("authenticateClient() client = %s, want jwt-client", client.ClientID)
+			}
+		})
+	}
+
+	// A private_key_jwt client cannot fall back to identifying itself without credentials
+	req := httptest.NewRequest(http.MethodPost, "/token", strings.NewReader("client_id=jwt-client"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.ParseForm()
+	if _, authErr := h.authenticateClient(req); authErr == nil {
+		t.Error("authenticateClient() without an assertion expected error")
+	}
+}
+
+// selfSignedCertificate creates a client certificate with the given common name
+func selfSignedCertificate(t *testing.T, commonName string) *x509.Certificate {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"Example"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	return cert
+}
+
+func TestTLSClientAuthentication(t *testing.T) {
+	cert := selfSignedCertificate(t, "mtls-client")
+	otherCert := selfSignedCertificate(t, "other-client")
+
+	h := newClientAuthHandler(t,
+		config.ClientConfig{
+			ClientID:                "dn-client",
+			TokenEndpointAuthMethod: "tls_client_auth",
+			TLSClientAuthSubjectDN:  "CN=mtls-client,O=Example",
+		},
+		config.ClientConfig{
+			ClientID:                "thumbprint-client",
+			TokenEndpointAuthMethod: "tls_client_auth",
+			TLSClientCertThumbprint: certificateThumbprint(cert),
+		},
+	)
+
+	tests := []struct {
+		name     string
+		clientID string
+		cert     *x509.Certificate
+		verified bool
+		wantErr  bool
+	}{
+		{"subject DN match", "dn-client", cert, true, false},
+		{"subject DN of an unverified certificate", "dn-client", cert, false, true},
+		{"subject DN mismatch", "dn-client", otherCert, true, true},
+		{"thumbprint match", "thumbprint-client", cert, false, false},
+		{"thumbprint mismatch", "thumbprint-client", otherCert, false, true},
+		{"no certificate", "dn-client", nil, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/token", strings.NewReader("client_id="+tt.clientID))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.ParseForm()
+			if tt.cert != nil {
+				req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{tt.cert}}
+			}
+			if tt.verified {
+				req.TLS.VerifiedChains = [][]*x509.Certificate{{tt.cert}}
+			}
+
+			_, authErr := h.authenticateClient(req)
+			if (authErr != nil) != tt.wantErr {
+				t.Errorf("authenticateClient() error = %v, wantErr %v", authErr, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shogotsuneto/jwks-mock-api/internal/device"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/logger"
+)
+
+// DeviceAuthorizationResponse represents a device authorization response (RFC 8628 section 3.2)
+type DeviceAuthorizationResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// devicePageData is rendered by the device verification page
+type devicePageData struct {
+	UserCode   string
+	ClientID   string
+	Scope      string
+	DefaultSub string
+	Message    string
+	Error      string
+}
+
+var devicePage = template.Must(template.New("device").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Device Verification</title></head>
+<body>
+<h1>Device Verification</h1>
+{{if .Message}}<p id="message">{{.Message}}</p>{{end}}
+{{if .Error}}<p id="error">{{.Error}}</p>{{end}}
+{{if not .Message}}
+<form method="POST" action="device">
+  <p><label>User code <input name="user_code" value="{{.UserCode}}"></label></p>
+  {{if .ClientID}}<p>Client <code>{{.ClientID}}</code> requests scope <code>{{.Scope}}</code></p>{{end}}
+  <p><label>Sign in as <input name="sub" value="{{.DefaultSub}}"></label></p>
+  <button type="submit" name="action" value="approve">Approve</button>
+  <button type="submit" name="action" value="deny">Deny</button>
+</form>
+{{end}}
+</body>
+</html>
+`))
+
+// DeviceAuthorization implements the device authorization endpoint (RFC 8628 section 3.1)
+func (h *Handler) DeviceAuthorization(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "Malformed form body")
+		return
+	}
+
+	clientID := requestClientID(r)
+	if clientID == "" {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "The client_id parameter is required")
+		return
+	}
+
+	expiresIn := time.Duration(h.config.DeviceFlow.ExpiresIn) * time.Second
+	interval := time.Duration(h.config.DeviceFlow.Interval) * time.Second
+
+	auth, err := h.devices.Create(clientID, r.PostFormValue("scope"), expiresIn, interval)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Error creating device authorization", "error", err)
+		writeOAuthError(w, http.StatusInternalServerError, "server_error", "Failed to create device authorization")
+		return
+	}
+
+	verificationURI := h.endpointURL("/device")
+	response := DeviceAuthorizationResponse{
+		DeviceCode:              auth.DeviceCode,
+		UserCode:                auth.UserCode,
+		VerificationURI:         verificationURI,
+		VerificationURIComplete: verificationURI + "?user_code=" + url.QueryEscape(auth.UserCode),
+		ExpiresIn:               h.config.DeviceFlow.ExpiresIn,
+		Interval:                h.config.DeviceFlow.Interval,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(response)
+}
+
+// defaultSubject returns the subject used when a user approves a request without
+// choosing one: the first configured user, or a generic test user
+func (h *Handler) defaultSubject() string {
+	if len(h.config.Users) > 0 {
+		return h.config.Users[0].Sub
+	}
+	return "test-user"
+}
+
+// renderDevicePage writes the device verification page
+func renderDevicePage(ctx context.Context, w http.ResponseWriter, status int, data devicePageData) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := devicePage.Execute(w, data); err != nil {
+		logger.ErrorContext(ctx, "Error rendering device page", "error", err)
+	}
+}
+
+// DeviceVerification serves the verification page (GET) and approves or denies a user code (POST)
+func (h *Handler) DeviceVerification(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		data := devicePageData{
+			UserCode:   r.URL.Query().Get("user_code"),
+			DefaultSub: h.defaultSubject(),
+		}
+		if data.UserCode != "" {
+			if auth, err := h.devices.Lookup(data.UserCode); err == nil {
+				data.ClientID = auth.ClientID
+				data.Scope = auth.Scope
+			} else {
+				data.Error = err.Error()
+			}
+		}
+		renderDevicePage(r.Context(), w, http.StatusOK, data)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		renderDevicePage(r.Context(), w, http.StatusBadRequest, devicePageData{Error: "Malformed form body"})
+		return
+	}
+
+	userCode := r.PostFormValue("user_code")
+	data := devicePageData{UserCode: userCode, DefaultSub: h.defaultSubject()}
+
+	var err error
+	switch r.PostFormValue("action") {
+	case "approve":
+		sub := r.PostFormValue("sub")
+		if sub == "" {
+			sub = data.DefaultSub
+		}
+		if err = h.devices.Approve(userCode, sub); err == nil {
+			data.Message = "Device approved. You may return to your device."
+		}
+	case "deny":
+		if err = h.devices.Deny(userCode); err == nil {
+			data.Message = "Device access denied."
+		}
+	default:
+		data.Error = "The action must be approve or deny"
+		renderDevicePage(r.Context(), w, http.StatusBadRequest, data)
+		return
+	}
+
+	if err != nil {
+		data.Error = err.Error()
+		renderDevicePage(r.Context(), w, http.StatusBadRequest, data)
+		return
+	}
+
+	renderDevicePage(r.Context(), w, http.StatusOK, data)
+}
+
+// deviceCodeGrant implements the device access token request (RFC 8628 section 3.4)
+func (h *Handler) deviceCodeGrant(w http.ResponseWriter, r *http.Request) {
+	deviceCode := r.PostFormValue("device_code")
+	clientID := requestClientID(r)
+	if deviceCode == "" || clientID == "" {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "device_code and client_id are required")
+		return
+	}
+
+	result, auth := h.devices.Poll(deviceCode, clientID)
+	switch result {
+	case device.PollPending:
+		writeOAuthError(w, http.StatusBadRequest, "authorization_pending", "The user has not yet completed authorization")
+		return
+	case device.PollSlowDown:
+		writeOAuthError(w, http.StatusBadRequest, "slow_down", "Polling too frequently, increase the interval by 5 seconds")
+		return
+	case device.PollDenied:
+		writeOAuthError(w, http.StatusBadRequest, "access_denied", "The user denied the authorization request")
+		return
+	case device.PollExpired:
+		writeOAuthError(w, http.StatusBadRequest, "expired_token", "The device_code has expired")
+		return
+	case device.PollInvalid:
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "The device_code is invalid")
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"sub":       auth.Subject,
+		"aud":       h.accessTokenAudience(r),
+		"client_id": auth.ClientID,
+		"jti":       newRandomID(),
+	}
+	scope := h.withoutAdminScope(auth.Scope)
+	if scope != "" {
+		claims["scope"] = scope
+	}
+	tokenType := bindAccessToken(r, claims)
+
+	tokenString, keyPair, err := h.issueToken(claims, defaultExpiresIn)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Error issuing device token", "error", err)
+		writeOAuthError(w, http.StatusInternalServerError, "server_error", "Failed to issue token")
+		return
+	}
+	logTokenFields(r, keyPair.Kid, claims)
+
+	writeTokenResponse(w, OAuthTokenResponse{
+		AccessToken: tokenString,
+		TokenType:   tokenType,
+		ExpiresIn:   defaultExpiresIn,
+		Scope:       scope,
+	})
+}
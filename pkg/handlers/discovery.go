@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// DiscoveryDocument represents the OpenID Connect Discovery 1.0 provider metadata
+type DiscoveryDocument struct {
+	Issuer                             string   `json:"issuer"`
+	JWKSURI                            string   `json:"jwks_uri"`
+	AuthorizationEndpoint              string   `json:"authorization_endpoint,omitempty"`
+	PushedAuthorizationEndpoint        string   `json:"pushed_authorization_request_endpoint,omitempty"`
+	RegistrationEndpoint               string   `json:"registration_endpoint,omitempty"`
+	TokenEndpoint                      string   `json:"token_endpoint,omitempty"`
+	DeviceAuthorizationEndpoint        string   `json:"device_authorization_endpoint,omitempty"`
+	IntrospectionEndpoint              string   `json:"introspection_endpoint,omitempty"`
+	IntrospectionSigningAlgValues      []string `json:"introspection_signing_alg_values_supported,omitempty"`
+	RevocationEndpoint                 string   `json:"revocation_endpoint,omitempty"`
+	UserInfoEndpoint                   string   `json:"userinfo_endpoint,omitempty"`
+	EndSessionEndpoint                 string   `json:"end_session_endpoint,omitempty"`
+	ScopesSupported                    []string `json:"scopes_supported,omitempty"`
+	ResponseTypesSupported             []string `json:"response_types_supported"`
+	GrantTypesSupported                []string `json:"grant_types_supported,omitempty"`
+	SubjectTypesSupported              []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported   []string `json:"id_token_signing_alg_values_supported"`
+	ClaimsSupported                    []string `json:"claims_supported,omitempty"`
+	TokenEndpointAuthMethods           []string `json:"token_endpoint_auth_methods_supported,omitempty"`
+	TokenEndpointAuthSigningAlgs       []string `json:"token_endpoint_auth_signing_alg_values_supported,omitempty"`
+	CodeChallengeMethodsSupported      []string `json:"code_challenge_methods_supported,omitempty"`
+	RequestParameterSupported          bool     `json:"request_parameter_supported"`
+	RequestURIParameterSupported       bool     `json:"request_uri_parameter_supported"`
+	RequestObjectSigningAlgValues      []string `json:"request_object_signing_alg_values_supported,omitempty"`
+	DPoPSigningAlgValues               []string `json:"dpop_signing_alg_values_supported,omitempty"`
+	BackchannelLogoutSupported         bool     `json:"backchannel_logout_supported"`
+	BackchannelLogoutSessionSupported  bool     `json:"backchannel_logout_session_supported"`
+	FrontchannelLogoutSupported        bool     `json:"frontchannel_logout_supported"`
+	FrontchannelLogoutSessionSupported bool     `json:"frontchannel_logout_session_supported"`
+}
+
+// endpointURL builds an absolute endpoint URL below the configured issuer
+func (h *Handler) endpointURL(path string) string {
+	return strings.TrimSuffix(h.config.JWT.Issuer, "/") + path
+}
+
+// OpenIDConfiguration returns the OpenID Connect discovery document
+func (h *Handler) OpenIDConfiguration(w http.ResponseWriter, r *http.Request) {
+	response := DiscoveryDocument{
+		Issuer:                           h.config.JWT.Issuer,
+		JWKSURI:                          h.endpointURL("/.well-known/jwks.json"),
+		AuthorizationEndpoint:            h.endpointURL("/authorize"),
+		PushedAuthorizationEndpoint:      h.endpointURL("/par"),
+		RegistrationEndpoint:             h.endpointURL("/register"),
+		TokenEndpoint:                    h.endpointURL("/token"),
+		DeviceAuthorizationEndpoint:      h.endpointURL("/device_authorization"),
+		IntrospectionEndpoint:            h.endpointURL("/introspect"),
+		IntrospectionSigningAlgValues:    []string{"RS256"},
+		RevocationEndpoint:               h.endpointURL("/revoke"),
+		UserInfoEndpoint:                 h.endpointURL("/userinfo"),
+		EndSessionEndpoint:               h.endpointURL("/end_session"),
+		ScopesSupported:                  []string{"openid", "profile", "email", "address", "phone", "roles", "groups"},
+		ResponseTypesSupported:           []string{"code"},
+		GrantTypesSupported:              supportedGrantTypes,
+		SubjectTypesSupported:            []string{"public"},
+		IDTokenSigningAlgValuesSupported: []string{"RS256"},
+		ClaimsSupported: []string{
+			"iss", "sub", "aud", "exp", "iat", "auth_time", "nonce",
+			"acr", "amr", "azp", "at_hash", "c_hash", "sid",
+		},
+		TokenEndpointAuthMethods:           tokenEndpointAuthMethods,
+		TokenEndpointAuthSigningAlgs:       clientSigningAlgs,
+		CodeChallengeMethodsSupported:      []string{"S256", "plain"},
+		RequestParameterSupported:          true,
+		RequestURIParameterSupported:       true,
+		RequestObjectSigningAlgValues:      clientSigningAlgs,
+		DPoPSigningAlgValues:               clientSigningAlgs,
+		BackchannelLogoutSupported:         true,
+		BackchannelLogoutSessionSupported:  true,
+		FrontchannelLogoutSupported:        true,
+		FrontchannelLogoutSessionSupported: true,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
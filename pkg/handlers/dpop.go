@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/logger"
+)
+
+// TokenTypeDPoP is the token_type of DPoP-bound access tokens (RFC 9449 section 5)
+const TokenTypeDPoP = "DPoP"
+
+// dpopProofType is the typ header of DPoP proof JWTs (RFC 9449 section 4.2)
+const dpopProofType = "dpop+jwt"
+
+// dpopNonceLifetime is how long a server-provided DPoP nonce is accepted
+const dpopNonceLifetime = 5 * time.Minute
+
+// dpopContextKey stores the JWK thumbprint of a validated DPoP proof in the request context
+type dpopContextKey struct{}
+
+// dpopProofError is returned when a DPoP proof is rejected
+type dpopProofError struct {
+	code        string // invalid_dpop_proof or use_dpop_nonce
+	description string
+}
+
+func (e *dpopProofError) Error() string {
+	return e.description
+}
+
+// checkDPoPProof validates the DPoP proof of a token request, if any, and returns the request
+// carrying the proof key's thumbprint. It writes an error response and returns false when
+// the proof is rejected.
+func (h *Handler) checkDPoPProof(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	proofs := r.Header.Values("DPoP")
+	if len(proofs) == 0 {
+		return r, true
+	}
+
+	jkt, proofErr := h.validateDPoPProof(r, proofs)
+	if proofErr != nil {
+		if proofErr.code == "use_dpop_nonce" {
+			h.setDPoPNonce(r.Context(), w)
+		}
+		writeOAuthError(w, http.StatusBadRequest, proofErr.code, proofErr.description)
+		return r, false
+	}
+
+	// Hand out a fresh nonce for the client's next proof
+	if h.config.DPoP.RequireNonce {
+		h.setDPoPNonce(r.Context(), w)
+	}
+	return r.WithContext(context.WithValue(r.Context(), dpopContextKey{}, jkt)), true
+}
+
+// setDPoPNonce sets a new server nonce in the DPoP-Nonce response header
+func (h *Handler) setDPoPNonce(ctx context.Context, w http.ResponseWriter) {
+	nonce, err := h.dpopNonces.Issue()
+	if err != nil {
+		logger.ErrorContext(ctx, "Error issuing DPoP nonce", "error", err)
+		return
+	}
+	w.Header().Set("DPoP-Nonce", nonce)
+}
+
+// validateDPoPProof checks a DPoP proof against the request (RFC 9449 section 4.3)
+// and returns the JWK SHA-256 thumbprint of its public key
+func (h *Handler) validateDPoPProof(r *http.Request, proofs []string) (string, *dpopProofError) {
+	if len(proofs) != 1 {
+		return "", &dpopProofError{"invalid_dpop_proof", "Exactly one DPoP header is allowed"}
+	}
+
+	var proofKey jwk.Key
+	token, err := jwt.Parse(proofs[0], func(token *jwt.Token) (interface{}, error) {
+		if typ, _ := token.Header["typ"].(string); typ != dpopProofType {
+			return nil, fmt.Errorf("typ must be %s", dpopProofType)
+		}
+		header, ok := token.Header["jwk"].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("the jwk header is required")
+		}
+		if _, private := header["d"]; private {
+			return nil, fmt.Errorf("the jwk header must not contain a private key")
+		}
+
+		data, err := json.Marshal(header)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jwk header: %w", err)
+		}
+		proofKey, err = jwk.ParseKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jwk header: %w", err)
+		}
+
+		var raw interface{}
+		if err := proofKey.Raw(&raw); err != nil {
+			return nil, fmt.Errorf("invalid jwk header: %w", err)
+		}
+		return raw, nil
+	}, jwt.WithValidMethods(clientSigningAlgs))
+	if err != nil {
+		return "", &dpopProofError{"invalid_dpop_proof", "Invalid DPoP proof: " + err.Error()}
+	}
+	claims := token.Claims.(jwt.MapClaims)
+
+	if htm, _ := claims["htm"].(string); htm != r.Method {
+		return "", &dpopProofError{"invalid_dpop_proof", "The DPoP proof htm does not match the request method"}
+	}
+	if htu, _ := claims["htu"].(string); !h.dpopTargetMatches(r, htu) {
+		return "", &dpopProofError{"invalid_dpop_proof", "The DPoP proof htu does not match the request URL"}
+	}
+
+	lifetime := time.Duration(h.config.DPoP.ProofLifetime) * time.Second
+	iat, err := claims.GetIssuedAt()
+	if err != nil || iat == nil {
+		return "", &dpopProofError{"invalid_dpop_proof", "The DPoP proof must have an iat claim"}
+	}
+	if age := time.Since(iat.Time); age > lifetime || age < -lifetime {
+		return "", &dpopProofError{"invalid_dpop_proof", "The DPoP proof iat is outside the accepted window"}
+	}
+
+	if h.config.DPoP.RequireNonce {
+		if nonce, _ := claims["nonce"].(string); nonce == "" || !h.dpopNonces.Valid(nonce) {
+			return "", &dpopProofError{"use_dpop_nonce", "Authorization server requires nonce in DPoP proof"}
+		}
+	}
+
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return "", &dpopProofError{"invalid_dpop_proof", "The DPoP proof must have a jti claim"}
+	}
+	if !h.dpopProofs.Use(jti, iat.Time.Add(lifetime)) {
+		return "", &dpopProofError{"invalid_dpop_proof", "The DPoP proof has already been used"}
+	}
+
+	thumbprint, err := proofKey.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return "", &dpopProofError{"invalid_dpop_proof", "Failed to compute the DPoP key thumbprint"}
+	}
+	return base64.RawURLEncoding.EncodeToString(thumbprint), nil
+}
+
+// dpopTargetMatches compares a proof's htu with the request URL, ignoring query and fragment.
+// Both the issuer-based URL and the URL the request was actually sent to are accepted.
+func (h *Handler) dpopTargetMatches(r *http.Request, htu string) bool {
+	target := normalizeHTU(htu)
+	if target == "" {
+		return false
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return target == normalizeHTU(h.endpointURL(r.URL.Path)) ||
+		target == normalizeHTU(scheme+"://"+r.Host+r.URL.Path)
+}
+
+// normalizeHTU lowercases the scheme and host of a URL and drops its query and fragment (RFC 9449 section 4.3)
+func normalizeHTU(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return ""
+	}
+	return strings.ToLower(parsed.Scheme) + "://" + strings.ToLower(parsed.Host) + parsed.Path
+}
+
+// bindAccessToken binds an access token to the request's DPoP key, if a proof was presented,
+// and returns the token type to report
+func bindAccessToken(r *http.Request, claims jwt.MapClaims) string {
+	jkt, _ := r.Context().Value(dpopContextKey{}).(string)
+	if jkt == "" {
+		return "Bearer"
+	}
+	claims["cnf"] = map[string]interface{}{"jkt": jkt}
+	return TokenTypeDPoP
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/config"
+)
+
+// dpopProof signs a DPoP proof with key, letting the caller adjust the claims
+func dpopProof(t *testing.T, key *ecdsa.PrivateKey, modify func(jwt.MapClaims)) string {
+	t.Helper()
+
+	publicKey, err := jwk.FromRaw(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to create JWK: %v", err)
+	}
+	data, _ := json.Marshal(publicKey)
+	var header map[string]interface{}
+	json.Unmarshal(data, &header)
+
+	claims := jwt.MapClaims{
+		"htm": http.MethodPost,
+		"htu": testIssuer + "/token",
+		"iat": time.Now().Unix(),
+		"jti": newRandomID(),
+	}
+	if modify != nil {
+		modify(claims)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["typ"] = "dpop+jwt"
+	token.Header["jwk"] = header
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign proof: %v", err)
+	}
+	return signed
+}
+
+// dpopThumbprint returns the JWK SHA-256 thumbprint of key
+func dpopThumbprint(t *testing.T, key *ecdsa.PrivateKey) string {
+	t.Helper()
+
+	publicKey, err := jwk.FromRaw(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to create JWK: %v", err)
+	}
+	thumbprint, err := publicKey.Thumbprint(crypto.SHA256)
+	if err != nil {
+		t.Fatalf("failed to compute thumbprint: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(thumbprint)
+}
+
+// dpopTokenRequest sends a password grant token request with the given DPoP proof
+func dpopTokenRequest(h *Handler, proof string) *httptest.ResponseRecorder {
+	form := url.Values{"grant_type": {"password"}, "username": {"alice"}, "password": {"secret"}}
+	req := httptest.NewRequest(http.MethodPost, "/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if proof != "" {
+		req.Header.Set("DPoP", proof)
+	}
+
+	rec := httptest.NewRecorder()
+	h.Token(rec, req)
+	return rec
+}
+
+// newDPoPHandler creates a handler with a password grant user
+func newDPoPHandler(t *testing.T, requireNonce bool) *Handler {
+	t.Helper()
+
+	return newTestHandler(t, &config.Config{
+		JWT:   config.JWTConfig{Issuer: testIssuer, Audience: "dev-api"},
+		Users: []config.UserConfig{{Sub: "alice", Password: "secret"}},
+		DPoP:  config.DPoPConfig{RequireNonce: requireNonce, ProofLifetime: 300},
+	})
+}
+
+func TestDPoPBoundTokens(t *testing.T) {
+	h := newDPoPHandler(t, false)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	proof := dpopProof(t, key, nil)
+	rec := dpopTokenRequest(h, proof)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Token() status = %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	var response OAuthTokenResponse
+	json.NewDecoder(rec.Body).Decode(&response)
+	if response.TokenType != TokenTypeDPoP {
+		t.Errorf("token_type = %s, want DPoP", response.TokenType)
+	}
+	claims, err := h.validateToken(response.AccessToken)
+	if err != nil {
+		t.Fatalf("issued token is invalid: %v", err)
+	}
+	cnf, _ := claims["cnf"].(map[string]interface{})
+	if cnf["jkt"] != dpopThumbprint(t, key) {
+		t.Errorf("cnf.jkt = %v, want the proof key thumbprint", cnf["jkt"])
+	}
+
+	// Without a proof the token is a plain bearer token
+	rec = dpopTokenRequest(h, "")
+	json.NewDecoder(rec.Body).Decode(&response)
+	if response.TokenType != "Bearer" {
+		t.Errorf("token_type without proof = %s, want Bearer", response.TokenType)
+	}
+}
+
+func TestDPoPProofValidation(t *testing.T) {
+	h := newDPoPHandler(t, false)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	replayed := dpopProof(t, key, nil)
+	dpopTokenRequest(h, replayed)
+
+	tests := []struct {
+		name  string
+		proof string
+	}{
+		{"replayed jti", replayed},
+		{"wrong method", dpopProof(t, key, func(c jwt.MapClaims) { c["htm"] = "GET" })},
+		{"wrong URL", dpopProof(t, key, func(c jwt.MapClaims) { c["htu"] = "https://elsewhere/token" })},
+		{"stale iat", dpopProof(t, key, func(c jwt.MapClaims) { c["iat"] = time.Now().Add(-time.Hour).Unix() })},
+		{"missing jti", dpopProof(t, key, func(c jwt.MapClaims) { delete(c, "jti") })},
+		{"not a JWT", "not-a-jwt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := dpopTokenRequest(h, tt.proof)
+			if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "invalid_dpop_proof") {
+				t.Errorf("Token() = %d %s, want 400 invalid_dpop_proof", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestDPoPNonceChallenge(t *testing.T) {
+	h := newDPoPHandler(t, true)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	rec := dpopTokenRequest(h, dpopProof(t, key, nil))
+	nonce := rec.Header().Get("DPoP-Nonce")
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "use_dpop_nonce") || nonce == "" {
+		t.Fatalf("Token() without nonce = %d %s (DPoP-Nonce %q), want a use_dpop_nonce challenge", rec.Code, rec.Body.String(), nonce)
+	}
+
+	rec = dpopTokenRequest(h, dpopProof(t, key, func(c jwt.MapClaims) { c["nonce"] = nonce }))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Token() with nonce = %d %s, want 200", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("DPoP-Nonce") == "" {
+		t.Error("Expected a fresh DPoP-Nonce on success")
+	}
+
+	rec = dpopTokenRequest(h, dpopProof(t, key, func(c jwt.MapClaims) { c["nonce"] = "made-up" }))
+	if !strings.Contains(rec.Body.String(), "use_dpop_nonce") {
+		t.Errorf("Token() with unknown nonce = %s, want use_dpop_nonce", rec.Body.String())
+	}
+}
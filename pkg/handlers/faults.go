@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/shogotsuneto/jwks-mock-api/internal/faults"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/logger"
+)
+
+// FaultsResponse represents the active fault listing
+type FaultsResponse struct {
+	TotalFaults int            `json:"total_faults"`
+	Faults      []faults.Fault `json:"faults"`
+}
+
+// FaultResponse represents the response for adding or removing a fault
+type FaultResponse struct {
+	Success bool          `json:"success"`
+	Message string        `json:"message"`
+	Fault   *faults.Fault `json:"fault,omitempty"`
+}
+
+// ClearFaultsResponse represents the response for clearing all faults
+type ClearFaultsResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Cleared int    `json:"cleared"`
+}
+
+// FaultInjection middleware applies the configured faults to matching requests. The
+// fault management and recorded request endpoints and CORS preflight requests are never faulted.
+func (h *Handler) FaultInjection(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions || r.URL.Path == "/faults" || strings.HasPrefix(r.URL.Path, "/faults/") ||
+			r.URL.Path == RecordedRequestsPath {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		fault := h.faults.Match(r.Method, r.URL.Path)
+		if fault == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		logger.DebugContext(r.Context(), "Injecting fault", "fault_id", fault.ID, "fault_type", fault.Type)
+
+		if fault.DelayMs > 0 {
+			select {
+			case <-time.After(time.Duration(fault.DelayMs) * time.Millisecond):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		switch fault.Type {
+		case faults.TypeLatency:
+			next.ServeHTTP(w, r)
+		case faults.TypeStatus:
+			writeFaultStatus(w, fault)
+		case faults.TypeReset:
+			resetConnection(w)
+		case faults.TypeEmptyKeys:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"keys":[]}`))
+		default:
+			serveCorrupted(w, r, next, fault)
+		}
+	})
+}
+
+// writeFaultStatus answers with the fault's error status, asking clients to retry after a
+// second on 429 and 503
+func writeFaultStatus(w http.ResponseWriter, fault *faults.Fault) {
+	code := "server_error"
+	switch {
+	case fault.Status == http.StatusTooManyRequests || fault.Status == http.StatusServiceUnavailable:
+		code = "temporarily_unavailable"
+		w.Header().Set("Retry-After", "1")
+	case fault.Status < 500:
+		code = "invalid_request"
+	}
+	writeOAuthError(w, fault.Status, code, "Injected fault "+fault.ID)
+}
+
+// resetConnection closes the client connection without sending a response. Lingering is
+// disabled so the client sees a TCP reset rather than an orderly close.
+func resetConnection(w http.ResponseWriter) {
+	conn, _, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		// Without access to the connection, abort the response instead
+		panic(http.ErrAbortHandler)
+	}
+
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		conn = tlsConn.NetConn()
+	}
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		tcpConn.SetLinger(0)
+	}
+	conn.Close()
+}
+
+// bufferedResponse captures a response so a fault can alter it before it is sent
+type bufferedResponse struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) Write(data []byte) (int, error) {
+	return b.body.Write(data)
+}
+
+func (b *bufferedResponse) WriteHeader(code int) {
+	b.statusCode = code
+}
+
+// serveCorrupted serves the request and corrupts the response according to the fault type
+func serveCorrupted(w http.ResponseWriter, r *http.Request, next http.Handler, fault *faults.Fault) {
+	response := &bufferedResponse{header: make(http.Header), statusCode: http.StatusOK}
+	next.ServeHTTP(response, r)
+
+	body := response.body.Bytes()
+	switch fault.Type {
+	case faults.TypeTruncate:
+		body = body[:len(body)/2]
+	case faults.TypeMalformed:
+		// Breaking the first key-value separator keeps the body recognisable but unparseable
+		if bytes.Contains(body, []byte(":")) {
+			body = bytes.Replace(body, []byte(":"), []byte("="), 1)
+		} else {
+			body = append([]byte("{"), body...)
+		}
+	case faults.TypeContentType:
+		response.header.Set("Content-Type", fault.ContentType)
+	}
+
+	for key, values := range response.header {
+		w.Header()[key] = values
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(response.statusCode)
+	w.Write(body)
+}
+
+// Faults handles GET /faults to list the active faults
+func (h *Handler) Faults(w http.ResponseWriter, r *http.Request) {
+	list := h.faults.List()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(FaultsResponse{
+		TotalFaults: len(list),
+		Faults:      list,
+	})
+}
+
+// AddFault handles POST /faults to activate a fault
+func (h *Handler) AddFault(w http.ResponseWriter, r *http.Request) {
+	var fault faults.Fault
+	if err := json.NewDecoder(r.Body).Decode(&fault); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(FaultResponse{
+			Success: false,
+			Message: fmt.Sprintf("Invalid JSON: %v", err),
+		})
+		return
+	}
+
+	added, err := h.faults.Add(fault)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(FaultResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	logger.InfoContext(r.Context(), "Added fault", "fault_id", added.ID, "fault_type", added.Type, "fault_path", added.Path)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(FaultResponse{
+		Success: true,
+		Message: "Fault added successfully",
+		Fault:   &added,
+	})
+}
+
+// RemoveFault handles DELETE /faults/{id} to deactivate a fault
+func (h *Handler) RemoveFault(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if !h.faults.Remove(id) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(FaultResponse{
+			Success: false,
+			Message: "Fault not found: " + id,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(FaultResponse{
+		Success: true,
+		Message: "Fault removed successfully",
+	})
+}
+
+// ClearFaults handles DELETE /faults to deactivate all faults
+func (h *Handler) ClearFaults(w http.ResponseWriter, r *http.Request) {
+	cleared := h.faults.Clear()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ClearFaultsResponse{
+		Success: true,
+		Message: "Faults cleared",
+		Cleared: cleared,
+	})
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shogotsuneto/jwks-mock-api/internal/faults"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/config"
+)
+
+func TestFaultInjection(t *testing.T) {
+	h := newTestHandler(t, &config.Config{JWT: config.JWTConfig{Issuer: testIssuer, Audience: "dev-api"}})
+	jwks := h.FaultInjection(http.HandlerFunc(h.JWKS))
+
+	tests := []struct {
+		name            string
+		fault           faults.Fault
+		wantStatus      int
+		wantContentType string
+		wantValidJSON   bool
+		wantKeys        int
+	}{
+		{"no fault", faults.Fault{}, http.StatusOK, "application/json", true, 1},
+		{"service unavailable", faults.Fault{Type: faults.TypeStatus, Status: 503}, http.StatusServiceUnavailable, "application/json", true, 0},
+		{"rate limited", faults.Fault{Type: faults.TypeStatus, Status: 429}, http.StatusTooManyRequests, "application/json", true, 0},
+		{"latency", faults.Fault{Type: faults.TypeLatency, DelayMs: 10}, http.StatusOK, "application/json", true, 1},
+		{"truncated", faults.Fault{Type: faults.TypeTruncate}, http.StatusOK, "application/json", false, 0},
+		{"malformed", faults.Fault{Type: faults.TypeMalformed}, http.StatusOK, "application/json", false, 0},
+		{"empty key set", faults.Fault{Type: faults.TypeEmptyKeys}, http.StatusOK, "application/json", true, 0},
+		{"wrong content type", faults.Fault{Type: faults.TypeContentType}, http.StatusOK, "text/html", true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.fault.Type != "" {
+				tt.fault.Path = "/.well-known/jwks.json"
+				tt.fault.Count = 1
+				if _, err := h.faults.Add(tt.fault); err != nil {
+					t.Fatalf("Add() unexpected error: %v", err)
+				}
+			}
+
+			rec := httptest.NewRecorder()
+			jwks.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if contentType := rec.Header().Get("Content-Type"); contentType != tt.wantContentType {
+				t.Errorf("Content-Type = %s, want %s", contentType, tt.wantContentType)
+			}
+
+			var body struct {
+				Keys []map[string]interface{} `json:"keys"`
+			}
+			err := json.Unmarshal(rec.Body.Bytes(), &body)
+			if (err == nil) != tt.wantValidJSON {
+				t.Fatalf("body JSON error = %v, wantValidJSON %v", err, tt.wantValidJSON)
+			}
+			if err == nil && len(body.Keys) != tt.wantKeys {
+				t.Errorf("keys = %d, want %d", len(body.Keys), tt.wantKeys)
+			}
+		})
+	}
+}
@@ -1,9 +1,13 @@
 package handlers
 
 import (
+	"bytes"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -11,15 +15,36 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/gorilla/mux"
+	"github.com/shogotsuneto/jwks-mock-api/internal/authz"
+	"github.com/shogotsuneto/jwks-mock-api/internal/clients"
+	"github.com/shogotsuneto/jwks-mock-api/internal/device"
+	"github.com/shogotsuneto/jwks-mock-api/internal/dpop"
+	"github.com/shogotsuneto/jwks-mock-api/internal/faults"
 	"github.com/shogotsuneto/jwks-mock-api/internal/keys"
+	"github.com/shogotsuneto/jwks-mock-api/internal/logout"
+	"github.com/shogotsuneto/jwks-mock-api/internal/metrics"
+	"github.com/shogotsuneto/jwks-mock-api/internal/recording"
+	"github.com/shogotsuneto/jwks-mock-api/internal/revocation"
 	"github.com/shogotsuneto/jwks-mock-api/pkg/config"
 	"github.com/shogotsuneto/jwks-mock-api/pkg/logger"
 )
 
 // Handler contains the HTTP handlers for the JWKS service
 type Handler struct {
-	config     *config.Config
-	keyManager *keys.Manager
+	config      *config.Config
+	keyManager  *keys.Manager
+	revocations *revocation.Store
+	devices     *device.Store
+	authz       *authz.Store
+	clients     *clients.Registry
+	assertions  *clients.ReplayCache
+	dpopProofs  *clients.ReplayCache
+	dpopNonces  *dpop.NonceStore
+	sessions    *logout.SessionStore
+	deliveries  *logout.DeliveryLog
+	faults      *faults.Store
+	metrics     *metrics.Registry
+	recordings  *recording.Store
 }
 
 // responseWriter wraps http.ResponseWriter to capture status code for access logging
@@ -34,11 +59,28 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap returns the wrapped writer, letting http.ResponseController reach the connection
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // New creates a new handler instance
 func New(cfg *config.Config, keyManager *keys.Manager) *Handler {
 	return &Handler{
-		config:     cfg,
-		keyManager: keyManager,
+		config:      cfg,
+		keyManager:  keyManager,
+		revocations: revocation.NewStore(),
+		devices:     device.NewStore(),
+		authz:       authz.NewStore(),
+		clients:     clients.NewRegistry(cfg.Clients),
+		assertions:  clients.NewReplayCache(),
+		dpopProofs:  clients.NewReplayCache(),
+		dpopNonces:  dpop.NewNonceStore(dpopNonceLifetime),
+		sessions:    logout.NewSessionStore(),
+		deliveries:  logout.NewDeliveryLog(),
+		faults:      faults.NewStore(),
+		metrics:     newMetrics(cfg, keyManager),
+		recordings:  recording.NewStore(cfg.Recording.Capacity),
 	}
 }
 
@@ -64,6 +106,8 @@ type IntrospectionResponse struct {
 	Aud       string `json:"aud,omitempty"`
 	Iss       string `json:"iss,omitempty"`
 	Jti       string `json:"jti,omitempty"`
+	// Confirmation claim of sender-constrained tokens (RFC 7800), e.g. jkt or x5t#S256
+	Cnf map[string]interface{} `json:"cnf,omitempty"`
 	// Additional claims from the original token
 	Claims map[string]interface{} `json:"-"` // Use custom marshaling to flatten
 }
@@ -109,12 +153,15 @@ func (r IntrospectionResponse) MarshalJSON() ([]byte, error) {
 	if r.Jti != "" {
 		result["jti"] = r.Jti
 	}
+	if len(r.Cnf) > 0 {
+		result["cnf"] = r.Cnf
+	}
 
 	// Add additional claims, avoiding overwriting standard fields
 	standardFields := map[string]bool{
 		"active": true, "token_type": true, "scope": true, "client_id": true,
 		"username": true, "exp": true, "iat": true, "nbf": true,
-		"sub": true, "aud": true, "iss": true, "jti": true,
+		"sub": true, "aud": true, "iss": true, "jti": true, "cnf": true,
 	}
 
 	for key, value := range r.Claims {
@@ -139,29 +186,44 @@ type KeysResponse struct {
 	AvailableKeys []map[string]interface{} `json:"available_keys"`
 }
 
-// JWKS returns the JSON Web Key Set
+// JWKS returns the JSON Web Key Set. The ETag and Last-Modified headers change whenever
+// the keys do, and conditional requests for an unchanged set get 304 Not Modified.
 func (h *Handler) JWKS(w http.ResponseWriter, r *http.Request) {
-	jwks, err := h.keyManager.GetJWKS()
+	jwks, modifiedAt, err := h.keyManager.JWKSDocument()
 	if err != nil {
-		logger.Errorf("Error generating JWKS: %v", err)
+		logger.ErrorContext(r.Context(), "Error generating JWKS", "error", err)
 		http.Error(w, `{"error": "Failed to generate JWKS"}`, http.StatusInternalServerError)
 		return
 	}
 
+	digest := sha256.Sum256(jwks)
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Header().Set("Cache-Control", h.jwksCacheControl())
+	w.Header().Set("ETag", `"`+base64.RawURLEncoding.EncodeToString(digest[:])+`"`)
 
-	if err := json.NewEncoder(w).Encode(jwks); err != nil {
-		logger.Errorf("Error encoding JWKS response: %v", err)
-		http.Error(w, `{"error": "Failed to encode JWKS"}`, http.StatusInternalServerError)
-		return
+	// ServeContent answers If-None-Match and If-Modified-Since and sets Last-Modified
+	http.ServeContent(w, r, "", modifiedAt, bytes.NewReader(jwks))
+}
+
+// jwksCacheControl returns the Cache-Control header of the JWKS endpoint
+func (h *Handler) jwksCacheControl() string {
+	if h.config.JWKS.NoStore {
+		return "no-store"
 	}
+	return fmt.Sprintf("public, max-age=%d", h.config.JWKS.MaxAge)
 }
 
 // TokenRequest represents the structure expected for token generation
 type TokenRequest struct {
 	Claims    map[string]interface{} `json:"claims"`
 	ExpiresIn *int                   `json:"expiresIn,omitempty"` // seconds
+	IDToken   *IDTokenOptions        `json:"idToken,omitempty"`   // generate an OpenID Connect ID token
+	DPoPJKT   string                 `json:"dpopJkt,omitempty"`   // bind the token to a DPoP key by its JWK SHA-256 thumbprint
+	Profile   string                 `json:"profile,omitempty"`   // access token profile, overriding jwt.profile
+
+	// Bind the token to a client certificate (RFC 8705), given as PEM or as its SHA-256 thumbprint
+	ClientCertificate     string `json:"clientCertificate,omitempty"`
+	CertificateThumbprint string `json:"certificateThumbprint,omitempty"`
 }
 
 // GenerateToken generates a new JWT token with dynamic claims
@@ -173,6 +235,18 @@ func (h *Handler) GenerateToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// ID tokens are not access tokens, so only access tokens follow the configured profile
+	if !isSupportedProfile(request.Profile) || (request.IDToken != nil && request.Profile == config.ProfileRFC9068) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("Unsupported profile for this token: %s", request.Profile)})
+		return
+	}
+	profile := h.profileOrDefault(request.Profile)
+	if request.IDToken != nil {
+		profile = config.ProfileJWT
+	}
+
 	// Extract expiresIn if present, default to 3600 seconds (1 hour)
 	expiresInSeconds := 3600
 	if request.ExpiresIn != nil {
@@ -193,7 +267,7 @@ func (h *Handler) GenerateToken(w http.ResponseWriter, r *http.Request) {
 	// Get a random key for signing
 	keyPair, err := h.keyManager.GetRandomKey()
 	if err != nil {
-		logger.Errorf("Error getting random key: %v", err)
+		logger.ErrorContext(r.Context(), "Error getting random key", "error", err)
 		http.Error(w, `{"error": "Failed to get signing key"}`, http.StatusInternalServerError)
 		return
 	}
@@ -217,17 +291,68 @@ func (h *Handler) GenerateToken(w http.ResponseWriter, r *http.Request) {
 		jwtClaims["aud"] = h.config.JWT.Audience
 	}
 
+	// Apply OpenID Connect ID token rules when an ID token is requested
+	if request.IDToken != nil {
+		if err := applyIDTokenClaims(jwtClaims, request.IDToken, keyPair.Alg); err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+	}
+
+	// Bind the token to a DPoP key (RFC 9449 section 6.1) and/or a client certificate
+	// (RFC 8705 section 3.1) through the confirmation claim
+	cnf := map[string]interface{}{}
+	if request.DPoPJKT != "" {
+		if thumbprint, err := base64.RawURLEncoding.DecodeString(request.DPoPJKT); err != nil || len(thumbprint) != sha256.Size {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "dpopJkt must be a base64url-encoded SHA-256 JWK thumbprint"})
+			return
+		}
+		cnf["jkt"] = request.DPoPJKT
+	}
+	if request.ClientCertificate != "" || request.CertificateThumbprint != "" {
+		thumbprint, err := certificateConfirmation(request.ClientCertificate, request.CertificateThumbprint)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		cnf["x5t#S256"] = thumbprint
+	}
+	if len(cnf) > 0 {
+		jwtClaims["cnf"] = cnf
+	}
+
+	// Complete RFC 9068 access tokens, which also require the caller to supply sub and client_id
+	if profile == config.ProfileRFC9068 {
+		if err := applyAccessTokenProfile(jwtClaims); err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+	}
+
 	// Create token
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwtClaims)
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(keyPair.Alg), jwtClaims)
 	token.Header["kid"] = keyPair.Kid
+	if profile == config.ProfileRFC9068 {
+		token.Header["typ"] = accessTokenType
+	}
 
 	// Sign token
 	tokenString, err := token.SignedString(keyPair.PrivateKey)
 	if err != nil {
-		logger.Errorf("Error signing token: %v", err)
+		logger.ErrorContext(r.Context(), "Error signing token", "error", err)
 		http.Error(w, `{"error": "Failed to sign token"}`, http.StatusInternalServerError)
 		return
 	}
+	h.metrics.TokenIssued(keyPair.Kid, keyPair.Alg)
+	logTokenFields(r, keyPair.Kid, jwtClaims)
 
 	response := TokenResponse{
 		Token:      tokenString,
@@ -242,6 +367,14 @@ func (h *Handler) GenerateToken(w http.ResponseWriter, r *http.Request) {
 
 // Introspect implements OAuth 2.0 Token Introspection (RFC 7662)
 func (h *Handler) Introspect(w http.ResponseWriter, r *http.Request) {
+	// Resource servers must authenticate unless the endpoint is configured to stay open
+	if h.config.Introspection.RequireAuth {
+		if _, ok := h.authenticateProtectedResource(r); !ok {
+			writeIntrospectionAuthError(w)
+			return
+		}
+	}
+
 	// Parse form data (RFC 7662 requires application/x-www-form-urlencoded)
 	if err := r.ParseForm(); err != nil {
 		response := IntrospectionResponse{Active: false}
@@ -253,81 +386,82 @@ func (h *Handler) Introspect(w http.ResponseWriter, r *http.Request) {
 
 	token := r.FormValue("token")
 	if token == "" {
-		response := IntrospectionResponse{Active: false}
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK) // RFC 7662: return 200 even for missing token
-		json.NewEncoder(w).Encode(response)
+		// RFC 7662: return 200 even for missing token
+		h.metrics.Introspected(false, reasonMissingToken)
+		recordTokenDecision(r, "", reasonMissingToken)
+		h.writeIntrospectionResponse(w, r, IntrospectionResponse{Active: false})
 		return
 	}
 
-	// Parse token to get the kid
-	parsedToken, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
-		// Validate the alg is RS256
-		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
+	response := IntrospectionResponse{}
 
-		// Get the kid from the token header
-		kid, ok := token.Header["kid"].(string)
-		if !ok {
-			return nil, fmt.Errorf("missing key ID in token header")
+	claims, err := h.validateToken(token)
+	reason := inactiveReason(err)
+	if err == nil && h.config.JWT.Profile == config.ProfileRFC9068 {
+		// Only RFC 9068 access tokens are active when the profile is enforced
+		err = checkAccessTokenProfile(token, claims)
+		reason = reasonProfile
+	}
+	if err != nil {
+		// Token is not active (invalid, expired, wrong issuer, etc.)
+		logger.DebugContext(r.Context(), "Introspected token is not active", "error", err)
+		h.metrics.Introspected(false, reason)
+		recordTokenDecision(r, token, reason)
+		response.Active = false
+	} else {
+		h.metrics.Introspected(true, "")
+		recordTokenDecision(r, token, "")
+		logTokenFields(r, tokenKeyID(token), claims)
+		// Token is active - populate response with claims
+		response.Active = true
+		response.TokenType = "Bearer"
+		if cnf, ok := claims["cnf"].(map[string]interface{}); ok {
+			response.Cnf = cnf
+			if cnf["jkt"] != nil {
+				response.TokenType = TokenTypeDPoP
+			}
 		}
 
-		// Find the corresponding key
-		keyPair, err := h.keyManager.GetKeyByID(kid)
-		if err != nil {
-			return nil, fmt.Errorf("key not found for kid: %s", kid)
+		// Map standard JWT claims to introspection response
+		if exp, ok := claims["exp"].(float64); ok {
+			response.Exp = int64(exp)
+		}
+		if iat, ok := claims["iat"].(float64); ok {
+			response.Iat = int64(iat)
+		}
+		if nbf, ok := claims["nbf"].(float64); ok {
+			response.Nbf = int64(nbf)
+		}
+		if sub, ok := claims["sub"].(string); ok {
+			response.Sub = sub
+			response.Username = sub // Use sub as username
+		}
+		if aud, ok := claims["aud"].(string); ok {
+			response.Aud = aud
+		}
+		if iss, ok := claims["iss"].(string); ok {
+			response.Iss = iss
+		}
+		if jti, ok := claims["jti"].(string); ok {
+			response.Jti = jti
 		}
 
-		return keyPair.PublicKey, nil
-	})
-
-	response := IntrospectionResponse{}
-
-	if err != nil || !parsedToken.Valid {
-		// Token is not active (invalid, expired, etc.)
-		response.Active = false
-	} else if claims, ok := parsedToken.Claims.(jwt.MapClaims); ok {
-		// Validate issuer (audience validation is more flexible for testing purposes)
-		if claims["iss"] != h.config.JWT.Issuer {
-			response.Active = false
-		} else {
-			// Token is active - populate response with claims
-			response.Active = true
-			response.TokenType = "Bearer"
+		// Add all other claims
+		response.Claims = make(map[string]interface{})
+		for key, value := range claims {
+			response.Claims[key] = value
+		}
+	}
 
-			// Map standard JWT claims to introspection response
-			if exp, ok := claims["exp"].(float64); ok {
-				response.Exp = int64(exp)
-			}
-			if iat, ok := claims["iat"].(float64); ok {
-				response.Iat = int64(iat)
-			}
-			if nbf, ok := claims["nbf"].(float64); ok {
-				response.Nbf = int64(nbf)
-			}
-			if sub, ok := claims["sub"].(string); ok {
-				response.Sub = sub
-				response.Username = sub // Use sub as username
-			}
-			if aud, ok := claims["aud"].(string); ok {
-				response.Aud = aud
-			}
-			if iss, ok := claims["iss"].(string); ok {
-				response.Iss = iss
-			}
-			if jti, ok := claims["jti"].(string); ok {
-				response.Jti = jti
-			}
+	h.writeIntrospectionResponse(w, r, response)
+}
 
-			// Add all other claims
-			response.Claims = make(map[string]interface{})
-			for key, value := range claims {
-				response.Claims[key] = value
-			}
-		}
-	} else {
-		response.Active = false
+// writeIntrospectionResponse writes an introspection response as JSON, or as a
+// signed JWT when the caller asked for one (RFC 9701)
+func (h *Handler) writeIntrospectionResponse(w http.ResponseWriter, r *http.Request, response IntrospectionResponse) {
+	if acceptsIntrospectionJWT(r) {
+		h.writeIntrospectionJWT(w, r, response)
+		return
 	}
 
 	// Always return 200 OK per RFC 7662
@@ -336,6 +470,66 @@ func (h *Handler) Introspect(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// Errors returned by validateToken besides the JWT validation errors
+var (
+	errUnknownKey       = errors.New("key not found")
+	errUnexpectedIssuer = errors.New("unexpected issuer")
+	errTokenRevoked     = errors.New("token has been revoked")
+)
+
+// validateToken verifies a token's signature against the managed keys, its expiry,
+// its issuer and its revocation status, returning the token claims when the token is active
+func (h *Handler) validateToken(token string) (jwt.MapClaims, error) {
+	// Parse token to get the kid
+	parsedToken, err := jwt.Parse(token, h.verificationKey)
+	if err != nil {
+		return nil, err
+	}
+	if !parsedToken.Valid {
+		return nil, fmt.Errorf("token is not valid")
+	}
+
+	claims, ok := parsedToken.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("unexpected claims type")
+	}
+
+	// Validate issuer (audience validation is more flexible for testing purposes)
+	if claims["iss"] != h.config.JWT.Issuer {
+		return nil, fmt.Errorf("%w: %v", errUnexpectedIssuer, claims["iss"])
+	}
+
+	// Reject tokens revoked through the revocation endpoint
+	jti, _ := claims["jti"].(string)
+	if h.revocations.IsRevoked(token, jti) {
+		return nil, errTokenRevoked
+	}
+
+	return claims, nil
+}
+
+// verificationKey returns the public key verifying a token, selected by its kid
+func (h *Handler) verificationKey(token *jwt.Token) (interface{}, error) {
+	// Validate the alg is RS256
+	if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	// Get the kid from the token header
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
+		return nil, fmt.Errorf("missing key ID in token header")
+	}
+
+	// Find the corresponding key
+	keyPair, err := h.keyManager.GetKeyByID(kid)
+	if err != nil {
+		return nil, fmt.Errorf("%w for kid: %s", errUnknownKey, kid)
+	}
+
+	return keyPair.PublicKey, nil
+}
+
 // GenerateInvalidToken generates an invalid JWT token for testing
 func (h *Handler) GenerateInvalidToken(w http.ResponseWriter, r *http.Request) {
 	// Parse the request body with the new structure
@@ -365,7 +559,7 @@ func (h *Handler) GenerateInvalidToken(w http.ResponseWriter, r *http.Request) {
 	// Get a valid key to use its kid
 	validKey, err := h.keyManager.GetRandomKey()
 	if err != nil {
-		logger.Errorf("Error getting random key: %v", err)
+		logger.ErrorContext(r.Context(), "Error getting random key", "error", err)
 		http.Error(w, `{"error": "Failed to get signing key"}`, http.StatusInternalServerError)
 		return
 	}
@@ -373,7 +567,7 @@ func (h *Handler) GenerateInvalidToken(w http.ResponseWriter, r *http.Request) {
 	// Generate a temporary invalid key pair
 	invalidPrivateKey, err := rsa.GenerateKey(rand.Reader, 2048)
 	if err != nil {
-		logger.Errorf("Error generating invalid key: %v", err)
+		logger.ErrorContext(r.Context(), "Error generating invalid key", "error", err)
 		http.Error(w, `{"error": "Failed to generate invalid key"}`, http.StatusInternalServerError)
 		return
 	}
@@ -404,7 +598,7 @@ func (h *Handler) GenerateInvalidToken(w http.ResponseWriter, r *http.Request) {
 	// Sign token with invalid key
 	tokenString, err := token.SignedString(invalidPrivateKey)
 	if err != nil {
-		logger.Errorf("Error signing invalid token: %v", err)
+		logger.ErrorContext(r.Context(), "Error signing invalid token", "error", err)
 		http.Error(w, `{"error": "Failed to sign invalid token"}`, http.StatusInternalServerError)
 		return
 	}
@@ -498,6 +692,7 @@ func (h *Handler) AddKey(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	h.metrics.KeyRotated(h.config.JWT.Issuer, metrics.EventKeyAdded)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -545,6 +740,7 @@ func (h *Handler) RemoveKey(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	h.metrics.KeyRotated(h.config.JWT.Issuer, metrics.EventKeyRemoved)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -555,10 +751,25 @@ func (h *Handler) RemoveKey(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// AccessLog middleware logs HTTP requests with basic access information
+// requestIDHeader carries the ID that correlates a request with its log lines
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds client-supplied request IDs, longer ones are replaced
+const maxRequestIDLength = 128
+
+// AccessLog middleware logs HTTP requests with basic access information. Each request
+// gets an ID, taken from the X-Request-ID header or generated, which is echoed in the
+// response and logged with every message about the request.
 func (h *Handler) AccessLog(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
+
+		requestID := r.Header.Get(requestIDHeader)
+		if !isValidRequestID(requestID) {
+			requestID = newRandomID()
+		}
+		w.Header().Set(requestIDHeader, requestID)
+		r = r.WithContext(logger.NewContext(r.Context(), requestID))
 		
 		// Wrap the response writer to capture status code
 		wrapped := &responseWriter{
@@ -590,22 +801,52 @@ func (h *Handler) AccessLog(next http.Handler) http.Handler {
 		// Calculate duration
 		duration := time.Since(start)
 		
-		// Log the access information
-		logger.Infof("%s %s %d %s %v", 
-			r.Method,
-			r.URL.Path, 
-			wrapped.statusCode,
-			clientIP,
-			duration)
+		// Log the access information, along with the fields handlers added to the request
+		logger.InfoContext(r.Context(), "request completed",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", wrapped.statusCode,
+			"client_ip", clientIP,
+			"duration_ms", float64(duration.Microseconds())/1000)
 	})
 }
 
+// isValidRequestID reports whether a client-supplied request ID is safe to log and echo
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// logTokenFields adds the signing key and subject of a token to the request's log fields
+func logTokenFields(r *http.Request, kid string, claims jwt.MapClaims) {
+	sub, _ := claims["sub"].(string)
+	logger.AddFields(r.Context(), "kid", kid, "sub", sub)
+}
+
+// tokenKeyID returns the kid header of a token without verifying it
+func tokenKeyID(token string) string {
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		return ""
+	}
+	kid, _ := parsed.Header["kid"].(string)
+	return kid
+}
+
 // CORS middleware
 func (h *Handler) CORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, DPoP, X-API-Key, X-Request-ID")
+		w.Header().Set("Access-Control-Expose-Headers", "DPoP-Nonce, WWW-Authenticate, X-Request-ID")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/shogotsuneto/jwks-mock-api/internal/keys"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/config"
+)
+
+const testIssuer = "http://localhost:3000"
+
+// newTestHandler creates a handler for cfg signing with a single generated key
+func newTestHandler(t *testing.T, cfg *config.Config) *Handler {
+	t.Helper()
+
+	keyManager := keys.NewManager()
+	if err := keyManager.GenerateKeys([]string{"test-key"}); err != nil {
+		t.Fatalf("failed to generate keys: %v", err)
+	}
+	return New(cfg, keyManager)
+}
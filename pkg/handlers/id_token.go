@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// IDTokenOptions holds the OpenID Connect settings used when generating an ID token
+type IDTokenOptions struct {
+	ClientID            string   `json:"clientId"`
+	Nonce               string   `json:"nonce,omitempty"`
+	AccessToken         string   `json:"accessToken,omitempty"` // used to compute at_hash
+	Code                string   `json:"code,omitempty"`        // used to compute c_hash
+	AuthTime            *int64   `json:"authTime,omitempty"`    // unix seconds, defaults to iat
+	ACR                 string   `json:"acr,omitempty"`
+	AMR                 []string `json:"amr,omitempty"`
+	AdditionalAudiences []string `json:"additionalAudiences,omitempty"`
+}
+
+// applyIDTokenClaims applies the OpenID Connect Core ID token rules to the given claims.
+// The hashes are computed with the hash function of the signing algorithm (alg).
+func applyIDTokenClaims(claims jwt.MapClaims, opts *IDTokenOptions, alg string) error {
+	if opts.ClientID == "" {
+		return fmt.Errorf("idToken.clientId is required")
+	}
+
+	if sub, ok := claims["sub"].(string); !ok || sub == "" {
+		return fmt.Errorf("ID tokens require a non-empty sub claim")
+	}
+
+	// The client ID must always be an audience. When other audiences are present,
+	// azp identifies the client the token was issued to.
+	if len(opts.AdditionalAudiences) == 0 {
+		claims["aud"] = opts.ClientID
+		delete(claims, "azp")
+	} else {
+		audiences := append([]string{opts.ClientID}, opts.AdditionalAudiences...)
+		claims["aud"] = audiences
+		claims["azp"] = opts.ClientID
+	}
+
+	// auth_time defaults to the issue time so that max_age checks have something to work with
+	if opts.AuthTime != nil {
+		claims["auth_time"] = *opts.AuthTime
+	} else if _, ok := claims["auth_time"]; !ok {
+		claims["auth_time"] = claims["iat"]
+	}
+
+	if opts.Nonce != "" {
+		claims["nonce"] = opts.Nonce
+	}
+	if opts.ACR != "" {
+		claims["acr"] = opts.ACR
+	}
+	if len(opts.AMR) > 0 {
+		claims["amr"] = opts.AMR
+	}
+
+	if opts.AccessToken != "" {
+		atHash, err := oidcHash(alg, opts.AccessToken)
+		if err != nil {
+			return err
+		}
+		claims["at_hash"] = atHash
+	}
+
+	if opts.Code != "" {
+		cHash, err := oidcHash(alg, opts.Code)
+		if err != nil {
+			return err
+		}
+		claims["c_hash"] = cHash
+	}
+
+	return nil
+}
+
+// oidcHash computes an at_hash/c_hash value: the base64url encoded left-most half
+// of the hash of the value, using the hash function of the JWS algorithm
+func oidcHash(alg, value string) (string, error) {
+	var hasher hash.Hash
+	switch {
+	case strings.HasSuffix(alg, "256"):
+		hasher = sha256.New()
+	case strings.HasSuffix(alg, "384"):
+		hasher = sha512.New384()
+	case strings.HasSuffix(alg, "512"):
+		hasher = sha512.New()
+	default:
+		return "", fmt.Errorf("unsupported signing algorithm for hash claims: %s", alg)
+	}
+
+	hasher.Write([]byte(value))
+	sum := hasher.Sum(nil)
+
+	return base64.RawURLEncoding.EncodeToString(sum[:len(sum)/2]), nil
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestOIDCHash(t *testing.T) {
+	tests := []struct {
+		name     string
+		alg      string
+		value    string
+		expected string
+		wantErr  bool
+	}{
+		{
+			// Example from OpenID Connect Core 1.0, Appendix A.3
+			name:     "RS256 at_hash",
+			alg:      "RS256",
+			value:    "jHkWEdUXMU1BwAsC4vtUsZwnNvTIxEl0z9K3vx5KF0Y",
+			expected: "77QmUPtjPfzWtF2AnpK9RQ",
+		},
+		{
+			// Example from OpenID Connect Core 1.0, Appendix A.4
+			name:     "RS256 c_hash",
+			alg:      "RS256",
+			value:    "Qcb0Orv1zh30vL1MPRsbm-diHiMwcLyZvn1arpZv-Jxf_11jnpEX3Tgfvk",
+			expected: "LDktKdoQak3Pk0cnXxCltA",
+		},
+		{
+			name:    "unsupported algorithm",
+			alg:     "none",
+			value:   "token",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := oidcHash(tt.alg, tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("oidcHash(%q) expected error, got %q", tt.alg, result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("oidcHash(%q) unexpected error: %v", tt.alg, err)
+			}
+			if result != tt.expected {
+				t.Errorf("oidcHash(%q, %q) = %q, want %q", tt.alg, tt.value, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestApplyIDTokenClaims(t *testing.T) {
+	claims := jwt.MapClaims{"sub": "user-1", "iat": int64(1000), "aud": "dev-api"}
+	opts := &IDTokenOptions{
+		ClientID:            "client-a",
+		Nonce:               "n-0S6_WzA2Mj",
+		AdditionalAudiences: []string{"client-b"},
+	}
+
+	if err := applyIDTokenClaims(claims, opts, "RS256"); err != nil {
+		t.Fatalf("applyIDTokenClaims unexpected error: %v", err)
+	}
+
+	aud, ok := claims["aud"].([]string)
+	if !ok || len(aud) != 2 || aud[0] != "client-a" {
+		t.Errorf("Expected aud to start with the client ID, got %v", claims["aud"])
+	}
+	if claims["azp"] != "client-a" {
+		t.Errorf("Expected azp to be the client ID, got %v", claims["azp"])
+	}
+	if claims["nonce"] != "n-0S6_WzA2Mj" {
+		t.Errorf("Expected nonce to be set, got %v", claims["nonce"])
+	}
+	if claims["auth_time"] != int64(1000) {
+		t.Errorf("Expected auth_time to default to iat, got %v", claims["auth_time"])
+	}
+
+	if err := applyIDTokenClaims(jwt.MapClaims{"sub": "user-1"}, &IDTokenOptions{}, "RS256"); err == nil {
+		t.Error("Expected error when clientId is missing")
+	}
+}
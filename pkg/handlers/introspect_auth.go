@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+)
+
+// authenticateProtectedResource checks the caller's basic auth or bearer credentials against
+// the configured protected resources, or basic auth against confidential clients in the client
+// registry, and returns the matching client ID
+func (h *Handler) authenticateProtectedResource(r *http.Request) (string, bool) {
+	clientID, clientSecret, hasBasic := r.BasicAuth()
+	bearer := ""
+	if !hasBasic {
+		bearer = bearerToken(r)
+	}
+
+	for _, resource := range h.config.Introspection.ProtectedResources {
+		if hasBasic && resource.ClientSecret != "" &&
+			secureCompare(clientID, resource.ClientID) && secureCompare(clientSecret, resource.ClientSecret) {
+			return resource.ClientID, true
+		}
+		if bearer != "" && resource.BearerToken != "" && secureCompare(bearer, resource.BearerToken) {
+			return resource.ClientID, true
+		}
+	}
+
+	if hasBasic {
+		if client, ok := h.clients.Get(clientID); ok && client.ClientSecret != "" && secureCompare(clientSecret, client.ClientSecret) {
+			return client.ClientID, true
+		}
+	}
+
+	return "", false
+}
+
+// secureCompare compares two secrets in constant time
+func secureCompare(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// writeIntrospectionAuthError writes a 401 response challenging the resource server to authenticate
+func writeIntrospectionAuthError(w http.ResponseWriter) {
+	w.Header().Add("WWW-Authenticate", `Basic realm="introspection"`)
+	w.Header().Add("WWW-Authenticate", `Bearer realm="introspection"`)
+	writeOAuthError(w, http.StatusUnauthorized, "invalid_client", "Protected resource authentication failed")
+}
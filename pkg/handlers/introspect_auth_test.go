@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/shogotsuneto/jwks-mock-api/pkg/config"
+)
+
+func TestIntrospectClientAuthentication(t *testing.T) {
+	cfg := &config.Config{
+		JWT: config.JWTConfig{Issuer: testIssuer, Audience: "dev-api"},
+		Introspection: config.IntrospectionConfig{
+			RequireAuth: true,
+			ProtectedResources: []config.ProtectedResourceConfig{
+				{ClientID: "resource-a", ClientSecret: "secret-a"},
+				{ClientID: "resource-b", BearerToken: "static-token-b"},
+			},
+		},
+		Clients: []config.ClientConfig{
+			{ClientID: "registered-client", ClientSecret: "client-secret"},
+			{ClientID: "public-client"},
+		},
+	}
+	h := newTestHandler(t, cfg)
+
+	tests := []struct {
+		name           string
+		setAuth        func(r *http.Request)
+		expectedStatus int
+	}{
+		{
+			name:           "missing credentials",
+			setAuth:        func(r *http.Request) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "valid basic auth",
+			setAuth:        func(r *http.Request) { r.SetBasicAuth("resource-a", "secret-a") },
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "wrong basic auth secret",
+			setAuth:        func(r *http.Request) { r.SetBasicAuth("resource-a", "wrong") },
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "valid bearer token",
+			setAuth:        func(r *http.Request) { r.Header.Set("Authorization", "Bearer static-token-b") },
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "registered client basic auth",
+			setAuth:        func(r *http.Request) { r.SetBasicAuth("registered-client", "client-secret") },
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "public client cannot authenticate",
+			setAuth:        func(r *http.Request) { r.SetBasicAuth("public-client", "") },
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "wrong bearer token",
+			setAuth:        func(r *http.Request) { r.Header.Set("Authorization", "Bearer wrong") },
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := url.Values{"token": {"some-token"}}.Encode()
+			req := httptest.NewRequest(http.MethodPost, "/introspect", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			tt.setAuth(req)
+
+			rec := httptest.NewRecorder()
+			h.Introspect(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("Introspect() status = %d, want %d", rec.Code, tt.expectedStatus)
+			}
+			if tt.expectedStatus == http.StatusUnauthorized {
+				if rec.Header().Get("WWW-Authenticate") == "" {
+					t.Error("Expected WWW-Authenticate header on 401 response")
+				}
+				if !strings.Contains(rec.Body.String(), "invalid_client") {
+					t.Errorf("Expected invalid_client error, got %s", rec.Body.String())
+				}
+			}
+		})
+	}
+
+	// The open mode ignores credentials entirely
+	cfg.Introspection.RequireAuth = false
+	body := url.Values{"token": {"some-token"}}.Encode()
+	req := httptest.NewRequest(http.MethodPost, "/introspect", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	h.Introspect(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Introspect() in open mode status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/logger"
+)
+
+// IntrospectionJWTContentType is the media type of JWT-secured introspection responses (RFC 9701)
+const IntrospectionJWTContentType = "application/token-introspection+jwt"
+
+// acceptsIntrospectionJWT reports whether the Accept header asks for a JWT introspection response
+func acceptsIntrospectionJWT(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err == nil && mediaType == IntrospectionJWTContentType {
+			return true
+		}
+	}
+	return false
+}
+
+// introspectionAudience identifies the resource server that requested introspection:
+// the authenticated protected resource, the basic auth client ID, or the configured audience
+func (h *Handler) introspectionAudience(r *http.Request) string {
+	if clientID, ok := h.authenticateProtectedResource(r); ok && clientID != "" {
+		return clientID
+	}
+	if clientID, _, ok := r.BasicAuth(); ok && clientID != "" {
+		return clientID
+	}
+	return h.config.JWT.Audience
+}
+
+// writeIntrospectionJWT writes the introspection response wrapped in a signed JWT (RFC 9701)
+func (h *Handler) writeIntrospectionJWT(w http.ResponseWriter, r *http.Request, response IntrospectionResponse) {
+	keyPair, err := h.keyManager.GetRandomKey()
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Error getting random key", "error", err)
+		http.Error(w, `{"error": "Failed to get signing key"}`, http.StatusInternalServerError)
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"iss":                 h.config.JWT.Issuer,
+		"aud":                 h.introspectionAudience(r),
+		"iat":                 time.Now().Unix(),
+		"token_introspection": response,
+	}
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(keyPair.Alg), claims)
+	token.Header["kid"] = keyPair.Kid
+	token.Header["typ"] = "token-introspection+jwt"
+
+	tokenString, err := token.SignedString(keyPair.PrivateKey)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Error signing introspection response", "error", err)
+		http.Error(w, `{"error": "Failed to sign introspection response"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", IntrospectionJWTContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(tokenString))
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shogotsuneto/jwks-mock-api/pkg/config"
+)
+
+// getJWKS calls the JWKS endpoint with the given request headers
+func getJWKS(h *Handler, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	rec := httptest.NewRecorder()
+	h.JWKS(rec, req)
+	return rec
+}
+
+func TestJWKSConditionalRequests(t *testing.T) {
+	h := newTestHandler(t, &config.Config{
+		JWT:  config.JWTConfig{Issuer: testIssuer, Audience: "dev-api"},
+		JWKS: config.JWKSConfig{MaxAge: 600},
+	})
+
+	first := getJWKS(h, nil)
+	etag := first.Header().Get("ETag")
+	lastModified := first.Header().Get("Last-Modified")
+	if first.Code != http.StatusOK || etag == "" || lastModified == "" {
+		t.Fatalf("JWKS() status = %d, ETag = %q, Last-Modified = %q", first.Code, etag, lastModified)
+	}
+	if cacheControl := first.Header().Get("Cache-Control"); cacheControl != "public, max-age=600" {
+		t.Errorf("JWKS() Cache-Control = %q, want public, max-age=600", cacheControl)
+	}
+	if second := getJWKS(h, nil); second.Header().Get("ETag") != etag || second.Body.String() != first.Body.String() {
+		t.Error("JWKS() of an unchanged key set is not stable")
+	}
+
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		wantStatus int
+	}{
+		{"matching ETag", map[string]string{"If-None-Match": etag}, http.StatusNotModified},
+		{"other ETag", map[string]string{"If-None-Match": `"other"`}, http.StatusOK},
+		{"not modified since", map[string]string{"If-Modified-Since": lastModified}, http.StatusNotModified},
+		{"modified since", map[string]string{"If-Modified-Since": "Mon, 01 Jan 2001 00:00:00 GMT"}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := getJWKS(h, tt.headers); rec.Code != tt.wantStatus {
+				t.Errorf("JWKS() status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+
+	// Changing the keys invalidates both validators
+	if err := h.keyManager.AddKey("new-key"); err != nil {
+		t.Fatalf("AddKey() unexpected error: %v", err)
+	}
+	rec := getJWKS(h, map[string]string{"If-None-Match": etag})
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag {
+		t.Errorf("JWKS() after AddKey status = %d, ETag = %s, want 200 with a new ETag", rec.Code, rec.Header().Get("ETag"))
+	}
+	// Last-Modified never runs ahead of the clock, even for changes within the same second
+	modified, err := http.ParseTime(rec.Header().Get("Last-Modified"))
+	if err != nil || modified.After(time.Now()) {
+		t.Errorf("JWKS() after AddKey Last-Modified = %q, want a time not after now", rec.Header().Get("Last-Modified"))
+	}
+
+	h.config.JWKS.NoStore = true
+	if cacheControl := getJWKS(h, nil).Header().Get("Cache-Control"); cacheControl != "no-store" {
+		t.Errorf("JWKS() with no_store Cache-Control = %q, want no-store", cacheControl)
+	}
+}
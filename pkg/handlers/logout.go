@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shogotsuneto/jwks-mock-api/internal/logout"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/config"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/logger"
+)
+
+// backChannelLogoutEvent identifies logout tokens in their events claim
+// (OpenID Connect Back-Channel Logout 1.0 section 2.4)
+const backChannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+
+// logoutTokenType is the typ header of logout tokens
+const logoutTokenType = "logout+jwt"
+
+// logoutTokenLifetime is the lifetime in seconds of logout tokens
+const logoutTokenLifetime = 120
+
+// LogoutDeliveriesResponse represents the logout delivery log listing
+type LogoutDeliveriesResponse struct {
+	TotalDeliveries int               `json:"total_deliveries"`
+	Deliveries      []logout.Delivery `json:"deliveries"`
+}
+
+// ClearLogoutDeliveriesResponse represents the response for clearing the logout delivery log
+type ClearLogoutDeliveriesResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Cleared int    `json:"cleared"`
+}
+
+// logoutPageData is rendered by the logout page
+type logoutPageData struct {
+	FrontChannelURIs []string
+	RedirectURI      string
+	Error            string
+}
+
+var logoutPage = template.Must(template.New("logout").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Logout</title></head>
+<body>
+{{if .Error}}
+<h1>Logout Failed</h1>
+<p id="error">{{.Error}}</p>
+{{else}}
+<h1>You have been logged out</h1>
+{{range .FrontChannelURIs}}<iframe src="{{.}}" style="display:none"></iframe>
+{{end}}
+{{if .RedirectURI}}
+<p><a id="continue" href="{{.RedirectURI}}">Continue</a></p>
+<script>window.onload = function() { window.location.href = {{.RedirectURI}}; };</script>
+{{end}}
+{{end}}
+</body>
+</html>
+`))
+
+// renderLogoutPage writes the logout page
+func renderLogoutPage(ctx context.Context, w http.ResponseWriter, status int, data logoutPageData) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := logoutPage.Execute(w, data); err != nil {
+		logger.ErrorContext(ctx, "Error rendering logout page", "error", err)
+	}
+}
+
+// EndSession implements the end session endpoint (OpenID Connect RP-Initiated Logout 1.0).
+// It ends the user's session and notifies its clients through back-channel logout tokens
+// and front-channel logout iframes.
+func (h *Handler) EndSession(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		renderLogoutPage(r.Context(), w, http.StatusBadRequest, logoutPageData{Error: "Malformed form body"})
+		return
+	}
+
+	subject := r.Form.Get("logout_hint")
+	clientID := r.Form.Get("client_id")
+	sid := ""
+	// Without an ID token, only a session of the named client can be ended, so a subject
+	// alone does not let anyone sign the user out of every client
+	sessionClientID := clientID
+	if subject != "" && clientID == "" && r.Form.Get("id_token_hint") == "" {
+		renderLogoutPage(r.Context(), w, http.StatusBadRequest, logoutPageData{Error: "The logout_hint parameter requires an id_token_hint or client_id"})
+		return
+	}
+	if hint := r.Form.Get("id_token_hint"); hint != "" {
+		claims, err := h.validateIDTokenHint(hint)
+		if err != nil {
+			renderLogoutPage(r.Context(), w, http.StatusBadRequest, logoutPageData{Error: "Invalid id_token_hint: " + err.Error()})
+			return
+		}
+		subject, _ = claims["sub"].(string)
+		sid, _ = claims["sid"].(string)
+
+		hintClientID := idTokenClientID(claims)
+		if clientID != "" && clientID != hintClientID {
+			renderLogoutPage(r.Context(), w, http.StatusBadRequest, logoutPageData{Error: "The client_id does not match the id_token_hint"})
+			return
+		}
+		clientID = hintClientID
+		sessionClientID = ""
+	}
+
+	redirectURI := r.Form.Get("post_logout_redirect_uri")
+	if redirectURI != "" {
+		client, ok := h.clients.Get(clientID)
+		if !ok || !containsString(client.PostLogoutRedirectURIs, redirectURI) {
+			renderLogoutPage(r.Context(), w, http.StatusBadRequest, logoutPageData{Error: "The post_logout_redirect_uri is not registered for the client"})
+			return
+		}
+		if state := r.Form.Get("state"); state != "" {
+			redirectURI = appendQuery(redirectURI, url.Values{"state": {state}})
+		}
+	}
+
+	// Notify every client of the session, plus the client the logout came from
+	var clientIDs []string
+	if session := h.sessions.End(sid, subject, sessionClientID); session != nil {
+		sid = session.ID
+		subject = session.Subject
+		clientIDs = session.Clients
+	}
+	if clientID != "" && !containsString(clientIDs, clientID) {
+		clientIDs = append(clientIDs, clientID)
+	}
+
+	var frontChannelURIs []string
+	for _, id := range clientIDs {
+		client, ok := h.clients.Get(id)
+		if !ok {
+			continue
+		}
+		// Logout tokens must identify the user by sub or sid
+		if client.BackchannelLogoutURI != "" && (subject != "" || sid != "") {
+			h.deliveries.Record(h.sendBackChannelLogout(r.Context(), client, subject, sid))
+		}
+		if client.FrontchannelLogoutURI != "" {
+			uri := h.frontChannelLogoutURI(client, sid)
+			frontChannelURIs = append(frontChannelURIs, uri)
+			h.deliveries.Record(logout.Delivery{
+				ClientID:    client.ClientID,
+				Channel:     logout.ChannelFrontChannel,
+				URI:         uri,
+				Subject:     subject,
+				SessionID:   sid,
+				DeliveredAt: time.Now().UTC(),
+			})
+		}
+	}
+	logger.DebugContext(r.Context(), "Ended session", "sub", subject, "sid", sid, "notified_clients", clientIDs)
+
+	// Front-channel iframes need a page to load in; otherwise redirect straight away
+	if len(frontChannelURIs) == 0 && redirectURI != "" {
+		http.Redirect(w, r, redirectURI, http.StatusFound)
+		return
+	}
+	renderLogoutPage(r.Context(), w, http.StatusOK, logoutPageData{FrontChannelURIs: frontChannelURIs, RedirectURI: redirectURI})
+}
+
+// validateIDTokenHint verifies the signature and issuer of an id_token_hint. Expired ID
+// tokens are accepted, as relying parties commonly log out after the ID token expired
+// (OpenID Connect RP-Initiated Logout 1.0 section 2).
+func (h *Handler) validateIDTokenHint(hint string) (jwt.MapClaims, error) {
+	parsedToken, err := jwt.Parse(hint, h.verificationKey, jwt.WithoutClaimsValidation())
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := parsedToken.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("unexpected claims type")
+	}
+	if claims["iss"] != h.config.JWT.Issuer {
+		return nil, fmt.Errorf("%w: %v", errUnexpectedIssuer, claims["iss"])
+	}
+	return claims, nil
+}
+
+// idTokenClientID returns the client an ID token was issued to, its azp or its first audience
+func idTokenClientID(claims jwt.MapClaims) string {
+	if azp, ok := claims["azp"].(string); ok && azp != "" {
+		return azp
+	}
+	switch aud := claims["aud"].(type) {
+	case string:
+		return aud
+	case []interface{}:
+		if len(aud) > 0 {
+			first, _ := aud[0].(string)
+			return first
+		}
+	}
+	return ""
+}
+
+// frontChannelLogoutURI returns the client's front-channel logout URI, with the iss and sid
+// parameters when the client requires them (OpenID Connect Front-Channel Logout 1.0 section 2)
+func (h *Handler) frontChannelLogoutURI(client *config.ClientConfig, sid string) string {
+	if !client.FrontchannelLogoutSessionRequired || sid == "" {
+		return client.FrontchannelLogoutURI
+	}
+	return appendQuery(client.FrontchannelLogoutURI, url.Values{"iss": {h.config.JWT.Issuer}, "sid": {sid}})
+}
+
+// issueLogoutToken issues a logout token for a client (OpenID Connect Back-Channel Logout 1.0 section 2.4)
+func (h *Handler) issueLogoutToken(clientID, subject, sid string) (string, error) {
+	keyPair, err := h.keyManager.GetRandomKey()
+	if err != nil {
+		return "", err
+	}
+
+	claims := jwt.MapClaims{
+		"aud":    clientID,
+		"jti":    newRandomID(),
+		"events": map[string]interface{}{backChannelLogoutEvent: map[string]interface{}{}},
+	}
+	if subject != "" {
+		claims["sub"] = subject
+	}
+	if sid != "" {
+		claims["sid"] = sid
+	}
+	h.setStandardClaims(claims, logoutTokenLifetime)
+
+	return h.signClaims(keyPair, claims, logoutTokenType)
+}
+
+// sendBackChannelLogout posts a logout token to the client's back-channel logout URI
+// (OpenID Connect Back-Channel Logout 1.0 section 2.5) and returns the delivery record
+func (h *Handler) sendBackChannelLogout(ctx context.Context, client *config.ClientConfig, subject, sid string) logout.Delivery {
+	delivery := logout.Delivery{
+		ClientID:    client.ClientID,
+		Channel:     logout.ChannelBackChannel,
+		URI:         client.BackchannelLogoutURI,
+		Subject:     subject,
+		SessionID:   sid,
+		DeliveredAt: time.Now().UTC(),
+	}
+
+	token, err := h.issueLogoutToken(client.ClientID, subject, sid)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error issuing logout token", "client_id", client.ClientID, "error", err)
+		delivery.Error = err.Error()
+		return delivery
+	}
+	delivery.LogoutToken = token
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	body := url.Values{"logout_token": {token}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, client.BackchannelLogoutURI, strings.NewReader(body))
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		logger.WarnContext(ctx, "Back-channel logout failed", "client_id", client.ClientID, "error", err)
+		delivery.Error = err.Error()
+		return delivery
+	}
+	resp.Body.Close()
+
+	delivery.StatusCode = resp.StatusCode
+	return delivery
+}
+
+// LogoutDeliveries handles GET /logout-deliveries to list the logout notifications
+func (h *Handler) LogoutDeliveries(w http.ResponseWriter, r *http.Request) {
+	deliveries := h.deliveries.List()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(LogoutDeliveriesResponse{
+		TotalDeliveries: len(deliveries),
+		Deliveries:      deliveries,
+	})
+}
+
+// ClearLogoutDeliveries handles DELETE /logout-deliveries to clear the logout delivery log
+func (h *Handler) ClearLogoutDeliveries(w http.ResponseWriter, r *http.Request) {
+	cleared := h.deliveries.Clear()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ClearLogoutDeliveriesResponse{
+		Success: true,
+		Message: "Logout delivery log cleared",
+		Cleared: cleared,
+	})
+}
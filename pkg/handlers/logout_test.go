@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shogotsuneto/jwks-mock-api/internal/logout"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/config"
+)
+
+func TestEndSessionNotifiesClients(t *testing.T) {
+	logoutTokens := make(chan string, 1)
+	rp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		logoutTokens <- r.PostFormValue("logout_token")
+	}))
+	defer rp.Close()
+
+	h := newTestHandler(t, &config.Config{
+		JWT:   config.JWTConfig{Issuer: testIssuer, Audience: "dev-api"},
+		Users: []config.UserConfig{{Sub: "alice", Password: "secret"}},
+		Clients: []config.ClientConfig{
+			{
+				ClientID:               "back-app",
+				GrantTypes:             []string{GrantTypePassword},
+				BackchannelLogoutURI:   rp.URL + "/logout",
+				PostLogoutRedirectURIs: []string{"https://back-app.example/bye"},
+			},
+			{
+				ClientID:                          "front-app",
+				GrantTypes:                        []string{GrantTypePassword},
+				FrontchannelLogoutURI:             "https://front-app.example/logout",
+				FrontchannelLogoutSessionRequired: true,
+			},
+		},
+	})
+
+	// Sign alice in to both clients, sharing one session
+	signIn := func(clientID string) OAuthTokenResponse {
+		form := url.Values{
+			"grant_type": {"password"}, "username": {"alice"}, "password": {"secret"},
+			"client_id": {clientID}, "scope": {"openid"},
+		}
+		req := httptest.NewRequest(http.MethodPost, "/token", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		h.Token(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Token() status = %d, body %s", rec.Code, rec.Body.String())
+		}
+		var response OAuthTokenResponse
+		json.NewDecoder(rec.Body).Decode(&response)
+		return response
+	}
+	signIn("front-app")
+	tokens := signIn("back-app")
+
+	idClaims, err := h.validateToken(tokens.IDToken)
+	if err != nil {
+		t.Fatalf("issued ID token is invalid: %v", err)
+	}
+	sid, _ := idClaims["sid"].(string)
+	if sid == "" {
+		t.Fatal("ID token has no sid claim")
+	}
+
+	query := url.Values{
+		"id_token_hint":            {tokens.IDToken},
+		"post_logout_redirect_uri": {"https://back-app.example/bye"},
+		"state":                    {"xyz"},
+	}
+	rec := httptest.NewRecorder()
+	h.EndSession(rec, httptest.NewRequest(http.MethodGet, "/end_session?"+query.Encode(), nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("EndSession() status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	page := rec.Body.String()
+	if !strings.Contains(page, `<iframe src="https://front-app.example/logout?iss=`) || !strings.Contains(page, "sid="+sid) {
+		t.Errorf("logout page has no front-channel iframe with iss and sid:\n%s", page)
+	}
+	if !strings.Contains(page, "https://back-app.example/bye?state=xyz") {
+		t.Errorf("logout page does not continue to the post-logout redirect URI:\n%s", page)
+	}
+
+	logoutClaims, err := h.validateToken(<-logoutTokens)
+	if err != nil {
+		t.Fatalf("logout token is invalid: %v", err)
+	}
+	events, _ := logoutClaims["events"].(map[string]interface{})
+	if _, ok := events[backChannelLogoutEvent]; !ok || logoutClaims["sid"] != sid || logoutClaims["sub"] != "alice" || logoutClaims["aud"] != "back-app" {
+		t.Errorf("logout token claims = %v", logoutClaims)
+	}
+	if _, ok := logoutClaims["nonce"]; ok {
+		t.Error("logout token must not contain a nonce")
+	}
+
+	deliveries := h.deliveries.List()
+	if len(deliveries) != 2 {
+		t.Fatalf("deliveries = %+v, want one per client", deliveries)
+	}
+	for _, delivery := range deliveries {
+		if delivery.Channel == logout.ChannelBackChannel && delivery.StatusCode != http.StatusOK {
+			t.Errorf("back-channel delivery = %+v, want status 200", delivery)
+		}
+	}
+
+	// The session has ended, so logging out again only reaches the hinted client
+	h.deliveries.Clear()
+	rec = httptest.NewRecorder()
+	h.EndSession(rec, httptest.NewRequest(http.MethodGet, "/end_session?"+query.Encode(), nil))
+	<-logoutTokens
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "https://back-app.example/bye?state=xyz" {
+		t.Errorf("EndSession() status = %d, Location = %s, want a redirect", rec.Code, rec.Header().Get("Location"))
+	}
+	if deliveries := h.deliveries.List(); len(deliveries) != 1 {
+		t.Errorf("deliveries after the session ended = %+v, want only back-app", deliveries)
+	}
+}
+
+func TestEndSessionRejectsUnregisteredRedirect(t *testing.T) {
+	h := newClientAuthHandler(t, config.ClientConfig{
+		ClientID:               "app",
+		PostLogoutRedirectURIs: []string{"https://app.example/bye"},
+	})
+
+	tests := []struct {
+		name       string
+		query      url.Values
+		wantStatus int
+	}{
+		{"registered redirect", url.Values{"client_id": {"app"}, "post_logout_redirect_uri": {"https://app.example/bye"}}, http.StatusFound},
+		{"unregistered redirect", url.Values{"client_id": {"app"}, "post_logout_redirect_uri": {"https://evil.example/"}}, http.StatusBadRequest},
+		{"redirect without client", url.Values{"post_logout_redirect_uri": {"https://app.example/bye"}}, http.StatusBadRequest},
+		{"invalid hint", url.Values{"id_token_hint": {"not-a-token"}}, http.StatusBadRequest},
+		{"logout hint without client", url.Values{"logout_hint": {"alice"}}, http.StatusBadRequest},
+		{"no parameters", url.Values{}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.EndSession(rec, httptest.NewRequest(http.MethodGet, "/end_session?"+tt.query.Encode(), nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("EndSession() status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestEndSessionAcceptsExpiredIDTokenHint(t *testing.T) {
+	h := newClientAuthHandler(t, config.ClientConfig{
+		ClientID:               "app",
+		PostLogoutRedirectURIs: []string{"https://app.example/bye"},
+	})
+	other := New(&config.Config{JWT: config.JWTConfig{Issuer: "http://elsewhere"}}, h.keyManager)
+
+	idToken := func(issuer *Handler, expiresIn int) string {
+		t.Helper()
+		token, err := issuer.issueIDToken(jwt.MapClaims{"sub": "alice"}, expiresIn, &IDTokenOptions{ClientID: "app"})
+		if err != nil {
+			t.Fatalf("failed to issue ID token: %v", err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name       string
+		hint       string
+		wantStatus int
+	}{
+		{"valid hint", idToken(h, 300), http.StatusFound},
+		{"expired hint", idToken(h, -3600), http.StatusFound},
+		{"other issuer", idToken(other, -3600), http.StatusBadRequest},
+		{"tampered signature", idToken(h, -3600) + "x", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := url.Values{"id_token_hint": {tt.hint}, "post_logout_redirect_uri": {"https://app.example/bye"}}
+			rec := httptest.NewRecorder()
+			h.EndSession(rec, httptest.NewRequest(http.MethodGet, "/end_session?"+query.Encode(), nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("EndSession() status = %d, want %d, body %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestEndSessionLogoutHint(t *testing.T) {
+	h := newClientAuthHandler(t, config.ClientConfig{ClientID: "app-a"}, config.ClientConfig{ClientID: "app-b"})
+	sid, err := h.sessions.Join("alice", "app-a")
+	if err != nil {
+		t.Fatalf("Join() unexpected error: %v", err)
+	}
+
+	endSession := func(clientID string) {
+		t.Helper()
+		query := url.Values{"logout_hint": {"alice"}, "client_id": {clientID}}
+		rec := httptest.NewRecorder()
+		h.EndSession(rec, httptest.NewRequest(http.MethodGet, "/end_session?"+query.Encode(), nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("EndSession() status = %d, body %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	// A client the user did not sign in to cannot end the session
+	endSession("app-b")
+	if again, _ := h.sessions.Join("alice", "app-a"); again != sid {
+		t.Fatal("EndSession() ended a session of another client")
+	}
+
+	endSession("app-a")
+	if again, _ := h.sessions.Join("alice", "app-a"); again == sid {
+		t.Error("EndSession() did not end the session of the client")
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
+	"github.com/shogotsuneto/jwks-mock-api/internal/keys"
+	"github.com/shogotsuneto/jwks-mock-api/internal/metrics"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/config"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/logger"
+)
+
+// Reasons introspected tokens are reported inactive, used as metric labels
+const (
+	reasonMissingToken = "missing_token"
+	reasonMalformed    = "malformed"
+	reasonExpired      = "expired"
+	reasonNotYetValid  = "not_yet_valid"
+	reasonSignature    = "invalid_signature"
+	reasonUnknownKey   = "unknown_key"
+	reasonIssuer       = "wrong_issuer"
+	reasonRevoked      = "revoked"
+	reasonProfile      = "profile"
+	reasonInvalid      = "invalid"
+)
+
+// newMetrics creates a metrics registry reporting the handler's key count
+func newMetrics(cfg *config.Config, keyManager *keys.Manager) *metrics.Registry {
+	registry := metrics.NewRegistry()
+	registry.TrackKeys(cfg.JWT.Issuer, keyManager.GetKeyCount)
+	return registry
+}
+
+// ShareMetrics makes the handler record its metrics in the registry of another handler,
+// so tenants are reported by the top-level /metrics endpoint. It must be called before
+// the handler serves requests.
+func (h *Handler) ShareMetrics(other *Handler) {
+	h.metrics = other.metrics
+}
+
+// UnshareMetrics gives the handler its own registry again, undoing ShareMetrics
+func (h *Handler) UnshareMetrics() {
+	h.metrics = newMetrics(h.config, h.keyManager)
+}
+
+// TrackKeyMetrics reports the handler's key count, once its issuer is registered
+func (h *Handler) TrackKeyMetrics() {
+	h.metrics.TrackKeys(h.config.JWT.Issuer, h.keyManager.GetKeyCount)
+}
+
+// ReleaseMetrics stops reporting the handler's key count, once its issuer is removed
+func (h *Handler) ReleaseMetrics() {
+	h.metrics.UntrackKeys(h.config.JWT.Issuer)
+}
+
+// inactiveReason classifies why validateToken rejected a token
+func inactiveReason(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, errUnknownKey):
+		return reasonUnknownKey
+	case errors.Is(err, errUnexpectedIssuer):
+		return reasonIssuer
+	case errors.Is(err, errTokenRevoked):
+		return reasonRevoked
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return reasonMalformed
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return reasonExpired
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return reasonNotYetValid
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid):
+		return reasonSignature
+	default:
+		return reasonInvalid
+	}
+}
+
+// RecordMetrics middleware records the count and latency of requests by route template
+// and status
+func (h *Handler) RecordMetrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		wrapped := &responseWriter{
+			ResponseWriter: w,
+			statusCode:     http.StatusOK,
+		}
+
+		// Routers nested under this one, such as the tenants', report their route through the context
+		route := new(string)
+		next.ServeHTTP(wrapped, r.WithContext(context.WithValue(r.Context(), routeKey{}, route)))
+
+		// Route templates keep the label cardinality low, e.g. /keys/{kid}
+		if *route == "" {
+			*route = routeTemplate(r, "unmatched")
+		}
+		h.metrics.ObserveRequest(r.Method, *route, wrapped.statusCode, time.Since(start))
+	})
+}
+
+// routeKey is the context key of the route template reported by nested routers
+type routeKey struct{}
+
+// RecordRoute returns a middleware reporting the route template matched by a router nested
+// under the top-level router, so RecordMetrics labels the request with it. The nested
+// router's path prefix is replaced with label, e.g. /tenants/a with /tenants/{tenant}, and
+// routes without a path template, such as host matchers, are labelled with label itself.
+func RecordRoute(prefix, label string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if route, ok := r.Context().Value(routeKey{}).(*string); ok {
+				*route = label + strings.TrimPrefix(routeTemplate(r, prefix), prefix)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// routeTemplate returns the path template of the route matched for the request, or fallback
+func routeTemplate(r *http.Request, fallback string) string {
+	if current := mux.CurrentRoute(r); current != nil {
+		if template, err := current.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+	return fallback
+}
+
+// Metrics handles GET /metrics in the Prometheus text exposition format
+func (h *Handler) Metrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", metrics.ContentType)
+	w.WriteHeader(http.StatusOK)
+	if err := h.metrics.Write(w); err != nil {
+		logger.ErrorContext(r.Context(), "Error writing metrics", "error", err)
+	}
+}
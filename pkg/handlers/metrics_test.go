@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/config"
+)
+
+func TestIntrospectionMetrics(t *testing.T) {
+	h := newTestHandler(t, &config.Config{JWT: config.JWTConfig{Issuer: testIssuer, Audience: "dev-api"}})
+	other := New(&config.Config{JWT: config.JWTConfig{Issuer: "http://elsewhere"}}, h.keyManager)
+
+	valid, _, err := h.issueToken(jwt.MapClaims{"sub": "user", "jti": "valid-jti"}, 300)
+	if err != nil {
+		t.Fatalf("failed to issue token: %v", err)
+	}
+	expired, _, err := h.issueToken(jwt.MapClaims{"sub": "user"}, -60)
+	if err != nil {
+		t.Fatalf("failed to issue token: %v", err)
+	}
+	wrongIssuer, _, err := other.issueToken(jwt.MapClaims{"sub": "user"}, 300)
+	if err != nil {
+		t.Fatalf("failed to issue token: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		wantReason string
+	}{
+		{"active", valid, ""},
+		{"malformed", "not-a-jwt", reasonMalformed},
+		{"expired", expired, reasonExpired},
+		{"wrong issuer", wrongIssuer, reasonIssuer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := h.validateToken(tt.token)
+			if reason := inactiveReason(err); reason != tt.wantReason {
+				t.Errorf("inactiveReason() = %q, want %q (error %v)", reason, tt.wantReason, err)
+			}
+		})
+	}
+
+	h.revocations.Revoke(valid, "valid-jti", "user", time.Now().Add(time.Hour))
+	if _, err := h.validateToken(valid); inactiveReason(err) != reasonRevoked {
+		t.Errorf("inactiveReason() of a revoked token = %q, want %q", inactiveReason(err), reasonRevoked)
+	}
+
+	// Tokens issued by the handler and its key count show up in /metrics
+	rec := httptest.NewRecorder()
+	h.Metrics(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`jwks_mock_tokens_issued_total{kid="test-key",alg="RS256"} 2`,
+		`jwks_mock_keys{issuer="` + testIssuer + `"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Metrics() output is missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shogotsuneto/jwks-mock-api/internal/keys"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/config"
+)
+
+// OAuthErrorResponse represents an OAuth 2.0 error response (RFC 6749 section 5.2)
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// writeOAuthError writes an OAuth 2.0 error response
+func writeOAuthError(w http.ResponseWriter, status int, errorCode, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(OAuthErrorResponse{
+		Error:            errorCode,
+		ErrorDescription: description,
+	})
+}
+
+// OAuthTokenResponse represents a successful token endpoint response (RFC 6749 section 5.1)
+type OAuthTokenResponse struct {
+	AccessToken     string `json:"access_token"`
+	IssuedTokenType string `json:"issued_token_type,omitempty"`
+	TokenType       string `json:"token_type"`
+	ExpiresIn       int    `json:"expires_in"`
+	Scope           string `json:"scope,omitempty"`
+	IDToken         string `json:"id_token,omitempty"`
+}
+
+// writeTokenResponse writes a successful token endpoint response
+func writeTokenResponse(w http.ResponseWriter, response OAuthTokenResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// newRandomID returns a random URL-safe identifier, used for jti values and codes
+func newRandomID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand never fails on supported platforms
+		panic(fmt.Sprintf("failed to read random bytes: %v", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// issueToken adds the standard iat, exp and iss claims and signs the access token with a
+// random key, following the configured access token profile
+func (h *Handler) issueToken(claims jwt.MapClaims, expiresIn int) (string, *keys.KeyPair, error) {
+	keyPair, err := h.keyManager.GetRandomKey()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get signing key: %w", err)
+	}
+
+	h.setStandardClaims(claims, expiresIn)
+
+	typ := ""
+	if h.config.JWT.Profile == config.ProfileRFC9068 {
+		if err := applyAccessTokenProfile(claims); err != nil {
+			return "", nil, err
+		}
+		typ = accessTokenType
+	}
+
+	tokenString, err := h.signClaims(keyPair, claims, typ)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return tokenString, keyPair, nil
+}
+
+// issueIDToken issues an OpenID Connect ID token, applying the ID token rules for the signing key
+func (h *Handler) issueIDToken(claims jwt.MapClaims, expiresIn int, opts *IDTokenOptions) (string, error) {
+	keyPair, err := h.keyManager.GetRandomKey()
+	if err != nil {
+		return "", fmt.Errorf("failed to get signing key: %w", err)
+	}
+
+	h.setStandardClaims(claims, expiresIn)
+	if err := applyIDTokenClaims(claims, opts, keyPair.Alg); err != nil {
+		return "", err
+	}
+
+	return h.signClaims(keyPair, claims, "")
+}
+
+// setStandardClaims sets the iat, exp and iss claims
+func (h *Handler) setStandardClaims(claims jwt.MapClaims, expiresIn int) {
+	now := time.Now()
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(time.Duration(expiresIn) * time.Second).Unix()
+	claims["iss"] = h.config.JWT.Issuer
+}
+
+// signClaims signs the claims with the given key pair, overriding the typ header when set
+func (h *Handler) signClaims(keyPair *keys.KeyPair, claims jwt.MapClaims, typ string) (string, error) {
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(keyPair.Alg), claims)
+	token.Header["kid"] = keyPair.Kid
+	if typ != "" {
+		token.Header["typ"] = typ
+	}
+
+	tokenString, err := token.SignedString(keyPair.PrivateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	h.metrics.TokenIssued(keyPair.Kid, keyPair.Alg)
+
+	return tokenString, nil
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+)
+
+// passwordGrant implements the resource owner password credentials grant (RFC 6749 section 4.3)
+// against the configured user directory
+func (h *Handler) passwordGrant(w http.ResponseWriter, r *http.Request) {
+	username := r.PostFormValue("username")
+	password := r.PostFormValue("password")
+	if username == "" || password == "" {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "username and password are required")
+		return
+	}
+
+	user := h.config.FindUserByUsername(username)
+	if user == nil || user.Password == "" || !secureCompare(password, user.Password) {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "Invalid username or password")
+		return
+	}
+
+	// Account state is only revealed once the credentials are correct
+	switch {
+	case user.Disabled:
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "Account is disabled")
+		return
+	case user.Locked:
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "Account is locked")
+		return
+	case user.PasswordExpired:
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "Password has expired")
+		return
+	}
+
+	h.writeUserTokenResponse(w, r, user, requestClientID(r), r.PostFormValue("scope"), "")
+}
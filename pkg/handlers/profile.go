@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/config"
+)
+
+// accessTokenType is the typ header of RFC 9068 access tokens
+const accessTokenType = "at+jwt"
+
+// rfc9068RequiredClaims lists the claims every RFC 9068 access token carries (RFC 9068 section 2.2)
+var rfc9068RequiredClaims = []string{"iss", "exp", "aud", "sub", "client_id", "iat", "jti"}
+
+// isSupportedProfile reports whether profile names a known access token profile
+func isSupportedProfile(profile string) bool {
+	return profile == "" || profile == config.ProfileJWT || profile == config.ProfileRFC9068
+}
+
+// profileOrDefault returns the requested profile, falling back to the configured one
+func (h *Handler) profileOrDefault(profile string) string {
+	if profile == "" {
+		return h.config.JWT.Profile
+	}
+	return profile
+}
+
+// applyAccessTokenProfile completes access token claims for RFC 9068, adding a jti when
+// missing, and returns an error when a claim the profile requires cannot be filled in
+func applyAccessTokenProfile(claims jwt.MapClaims) error {
+	if _, ok := claims["jti"]; !ok {
+		claims["jti"] = newRandomID()
+	}
+	for _, name := range rfc9068RequiredClaims {
+		if value, ok := claims[name]; !ok || value == "" {
+			return fmt.Errorf("RFC 9068 access tokens require the %s claim", name)
+		}
+	}
+	return nil
+}
+
+// checkAccessTokenProfile verifies that a validated token follows RFC 9068: the at+jwt type
+// and the required claims (RFC 9068 section 4)
+func checkAccessTokenProfile(token string, claims jwt.MapClaims) error {
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		return err
+	}
+	typ, _ := parsed.Header["typ"].(string)
+	if !strings.EqualFold(typ, accessTokenType) && !strings.EqualFold(typ, "application/"+accessTokenType) {
+		return fmt.Errorf("unexpected token type: %q", typ)
+	}
+	for _, name := range rfc9068RequiredClaims {
+		if value, ok := claims[name]; !ok || value == "" {
+			return fmt.Errorf("missing %s claim", name)
+		}
+	}
+	return nil
+}
+
+// checkProfileClient rejects token requests without a client when RFC 9068 access tokens,
+// which always carry client_id, are configured, writing an error response
+func (h *Handler) checkProfileClient(w http.ResponseWriter, r *http.Request) bool {
+	if h.config.JWT.Profile != config.ProfileRFC9068 || requestClientID(r) != "" {
+		return true
+	}
+	writeOAuthError(w, http.StatusBadRequest, "invalid_request", "client_id is required to issue RFC 9068 access tokens")
+	return false
+}
+
+// accessTokenAudience returns the aud claim for a token endpoint access token. In RFC 9068
+// mode the resource indicators of the request (RFC 8707) name the audience.
+func (h *Handler) accessTokenAudience(r *http.Request) interface{} {
+	if h.config.JWT.Profile == config.ProfileRFC9068 {
+		switch resources := r.PostForm["resource"]; len(resources) {
+		case 0:
+		case 1:
+			return resources[0]
+		default:
+			return resources
+		}
+	}
+	return h.config.JWT.Audience
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/config"
+)
+
+// newProfileHandler creates a handler issuing tokens in the given profile
+func newProfileHandler(t *testing.T, profile string) *Handler {
+	t.Helper()
+
+	return newTestHandler(t, &config.Config{
+		JWT:   config.JWTConfig{Issuer: testIssuer, Audience: "dev-api", Profile: profile},
+		Users: []config.UserConfig{{Sub: "alice", Password: "secret"}},
+	})
+}
+
+// generateToken calls /generate-token and returns the recorded response
+func generateToken(h *Handler, request TokenRequest) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(request)
+	rec := httptest.NewRecorder()
+	h.GenerateToken(rec, httptest.NewRequest(http.MethodPost, "/generate-token", bytes.NewReader(body)))
+	return rec
+}
+
+// introspectActive reports whether /introspect considers the token active
+func introspectActive(t *testing.T, h *Handler, token string) bool {
+	t.Helper()
+
+	form := url.Values{"token": {token}}
+	req := httptest.NewRequest(http.MethodPost, "/introspect", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	h.Introspect(rec, req)
+
+	var response map[string]interface{}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode introspection response: %v", err)
+	}
+	return response["active"] == true
+}
+
+func TestGenerateTokenProfile(t *testing.T) {
+	tests := []struct {
+		name       string
+		global     string
+		request    TokenRequest
+		wantStatus int
+		wantTyp    string
+	}{
+		{
+			name:       "plain by default",
+			global:     config.ProfileJWT,
+			request:    TokenRequest{Claims: map[string]interface{}{"sub": "user"}},
+			wantStatus: http.StatusOK,
+			wantTyp:    "JWT",
+		},
+		{
+			name:       "per request profile",
+			global:     config.ProfileJWT,
+			request:    TokenRequest{Claims: map[string]interface{}{"sub": "user", "client_id": "app"}, Profile: config.ProfileRFC9068},
+			wantStatus: http.StatusOK,
+			wantTyp:    accessTokenType,
+		},
+		{
+			name:       "global profile",
+			global:     config.ProfileRFC9068,
+			request:    TokenRequest{Claims: map[string]interface{}{"sub": "user", "client_id": "app"}},
+			wantStatus: http.StatusOK,
+			wantTyp:    accessTokenType,
+		},
+		{
+			name:       "per request opt out",
+			global:     config.ProfileRFC9068,
+			request:    TokenRequest{Claims: map[string]interface{}{"sub": "user"}, Profile: config.ProfileJWT},
+			wantStatus: http.StatusOK,
+			wantTyp:    "JWT",
+		},
+		{
+			name:       "missing client_id",
+			global:     config.ProfileRFC9068,
+			request:    TokenRequest{Claims: map[string]interface{}{"sub": "user"}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown profile",
+			global:     config.ProfileJWT,
+			request:    TokenRequest{Claims: map[string]interface{}{"sub": "user"}, Profile: "custom"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "ID token with RFC 9068 profile",
+			global:     config.ProfileJWT,
+			request:    TokenRequest{IDToken: &IDTokenOptions{ClientID: "app"}, Profile: config.ProfileRFC9068},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newProfileHandler(t, tt.global)
+			rec := generateToken(h, tt.request)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("GenerateToken() status = %d, want %d, body %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var response TokenResponse
+			json.NewDecoder(rec.Body).Decode(&response)
+			token, _, err := jwt.NewParser().ParseUnverified(response.Token, jwt.MapClaims{})
+			if err != nil {
+				t.Fatalf("failed to parse token: %v", err)
+			}
+			if token.Header["typ"] != tt.wantTyp {
+				t.Errorf("typ = %v, want %s", token.Header["typ"], tt.wantTyp)
+			}
+			if tt.wantTyp == accessTokenType && token.Claims.(jwt.MapClaims)["jti"] == nil {
+				t.Error("RFC 9068 token has no jti claim")
+			}
+		})
+	}
+}
+
+func TestIntrospectEnforcesProfile(t *testing.T) {
+	h := newProfileHandler(t, config.ProfileRFC9068)
+
+	var plain, profiled TokenResponse
+	json.NewDecoder(generateToken(h, TokenRequest{
+		Claims:  map[string]interface{}{"sub": "user", "client_id": "app", "jti": "plain-token"},
+		Profile: config.ProfileJWT,
+	}).Body).Decode(&plain)
+	json.NewDecoder(generateToken(h, TokenRequest{
+		Claims: map[string]interface{}{"sub": "user", "client_id": "app"},
+	}).Body).Decode(&profiled)
+
+	if introspectActive(t, h, plain.Token) {
+		t.Error("Introspect() reported a plain JWT active under the RFC 9068 profile")
+	}
+	if !introspectActive(t, h, profiled.Token) {
+		t.Error("Introspect() reported an RFC 9068 token inactive")
+	}
+}
+
+func TestTokenEndpointProfile(t *testing.T) {
+	h := newProfileHandler(t, config.ProfileRFC9068)
+
+	tokenRequest := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/token", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		h.Token(rec, req)
+		return rec
+	}
+
+	// RFC 9068 tokens always name the client
+	rec := tokenRequest(url.Values{"grant_type": {"password"}, "username": {"alice"}, "password": {"secret"}})
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Token() without client_id status = %d, want 400", rec.Code)
+	}
+
+	rec = tokenRequest(url.Values{
+		"grant_type": {"password"}, "username": {"alice"}, "password": {"secret"},
+		"client_id": {"app"}, "scope": {"api"}, "resource": {"https://api.example"},
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Token() status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	var response OAuthTokenResponse
+	json.NewDecoder(rec.Body).Decode(&response)
+
+	token, _, err := jwt.NewParser().ParseUnverified(response.AccessToken, jwt.MapClaims{})
+	if err != nil {
+		t.Fatalf("failed to parse token: %v", err)
+	}
+	claims := token.Claims.(jwt.MapClaims)
+	if token.Header["typ"] != accessTokenType {
+		t.Errorf("typ = %v, want %s", token.Header["typ"], accessTokenType)
+	}
+	if claims["aud"] != "https://api.example" {
+		t.Errorf("aud = %v, want the requested resource", claims["aud"])
+	}
+	if claims["client_id"] != "app" || claims["scope"] != "api" {
+		t.Errorf("client_id = %v, scope = %v, want app and api", claims["client_id"], claims["scope"])
+	}
+	if !introspectActive(t, h, response.AccessToken) {
+		t.Error("Introspect() reported the issued token inactive")
+	}
+}
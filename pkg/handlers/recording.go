@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/shogotsuneto/jwks-mock-api/internal/recording"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/logger"
+)
+
+// RecordedRequestsPath is the endpoint listing and clearing the recorded requests
+const RecordedRequestsPath = "/admin/requests"
+
+// RecordedRequestsResponse represents the recorded request listing
+type RecordedRequestsResponse struct {
+	TotalRequests int               `json:"total_requests"`
+	Capacity      int               `json:"capacity"`
+	Requests      []recording.Entry `json:"requests"`
+}
+
+// RecordedRequestsErrorResponse represents an invalid recorded request filter
+type RecordedRequestsErrorResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// ClearRecordedRequestsResponse represents the response for clearing the recorded requests
+type ClearRecordedRequestsResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Cleared int    `json:"cleared"`
+}
+
+// recordingWriter captures the status, headers and a bounded copy of the body of a response
+type recordingWriter struct {
+	http.ResponseWriter
+	response recording.Response
+	body     bytes.Buffer
+	limit    int
+	written  bool
+}
+
+// WriteHeader captures the status code and headers sent
+func (rw *recordingWriter) WriteHeader(code int) {
+	if !rw.written {
+		rw.written = true
+		rw.response.Status = code
+		rw.response.Headers = rw.Header().Clone()
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+// Write captures the body up to the recording limit
+func (rw *recordingWriter) Write(b []byte) (int, error) {
+	if !rw.written {
+		rw.WriteHeader(http.StatusOK)
+	}
+	if room := rw.limit - rw.body.Len(); room < len(b) {
+		rw.body.Write(b[:max(room, 0)])
+		rw.response.BodyTruncated = true
+	} else {
+		rw.body.Write(b)
+	}
+	return rw.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the wrapped writer, letting http.ResponseController reach the connection
+func (rw *recordingWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// RecordRequests middleware records each request and its response for inspection through
+// GET /admin/requests. Requests to the recording endpoint itself are not recorded.
+func (h *Handler) RecordRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.recordings.Capacity() == 0 || r.URL.Path == RecordedRequestsPath {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		limit := h.config.Recording.MaxBodyBytes
+		entry := &recording.Entry{
+			RequestID: logger.RequestID(r.Context()),
+			Time:      start,
+			Method:    r.Method,
+			Host:      r.Host,
+			Path:      r.URL.Path,
+			Query:     r.URL.RawQuery,
+			Headers:   r.Header.Clone(),
+		}
+		entry.Body, entry.BodyTruncated = captureRequestBody(r, limit)
+
+		wrapped := &recordingWriter{ResponseWriter: w, limit: limit}
+		next.ServeHTTP(wrapped, r.WithContext(recording.NewContext(r.Context(), entry)))
+
+		if !wrapped.written {
+			// Nothing was written, so net/http answers 200 with the headers set so far
+			wrapped.response.Status = http.StatusOK
+			wrapped.response.Headers = w.Header().Clone()
+		}
+		entry.Response = wrapped.response
+		entry.Response.Body = wrapped.body.String()
+		entry.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+		h.recordings.Add(entry)
+	})
+}
+
+// captureRequestBody reads up to limit bytes of the request body for the recording,
+// leaving the full body for the handler to read
+func captureRequestBody(r *http.Request, limit int) (string, bool) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return "", false
+	}
+
+	captured, _ := io.ReadAll(io.LimitReader(r.Body, int64(limit)+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(captured), r.Body), r.Body}
+
+	if len(captured) > limit {
+		return string(captured[:limit]), true
+	}
+	return string(captured), false
+}
+
+// recordTokenDecision records the token presented with a request and whether it was
+// accepted, given the reason it was rejected
+func recordTokenDecision(r *http.Request, token, reason string) {
+	decision := recording.DecisionActive
+	if reason != "" {
+		decision = recording.DecisionInactive
+	}
+	recording.RecordToken(r.Context(), token, decision, reason)
+}
+
+// RecordedRequests handles GET /admin/requests to list the recorded requests, filtered
+// by the method, path, status, decision, since and limit query parameters
+func (h *Handler) RecordedRequests(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := recording.Filter{
+		Method:   query.Get("method"),
+		Path:     query.Get("path"),
+		Decision: query.Get("decision"),
+	}
+
+	var err error
+	if filter.Status, err = intParam(query.Get("status")); err != nil {
+		writeRecordedRequestsError(w, "Invalid status: "+err.Error())
+		return
+	}
+	if filter.Limit, err = intParam(query.Get("limit")); err != nil {
+		writeRecordedRequestsError(w, "Invalid limit: "+err.Error())
+		return
+	}
+	since, err := intParam(query.Get("since"))
+	if err != nil {
+		writeRecordedRequestsError(w, "Invalid since: "+err.Error())
+		return
+	}
+	filter.Since = int64(since)
+
+	requests := h.recordings.List(filter)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(RecordedRequestsResponse{
+		TotalRequests: len(requests),
+		Capacity:      h.recordings.Capacity(),
+		Requests:      requests,
+	})
+}
+
+// ClearRecordedRequests handles DELETE /admin/requests to clear the recorded requests
+func (h *Handler) ClearRecordedRequests(w http.ResponseWriter, r *http.Request) {
+	cleared := h.recordings.Clear()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ClearRecordedRequestsResponse{
+		Success: true,
+		Message: "Recorded requests cleared",
+		Cleared: cleared,
+	})
+}
+
+// intParam parses an optional non-negative integer query parameter
+func intParam(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%q is not a non-negative integer", value)
+	}
+	return n, nil
+}
+
+// writeRecordedRequestsError writes a 400 response for an invalid filter
+func writeRecordedRequestsError(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(RecordedRequestsErrorResponse{
+		Success: false,
+		Message: message,
+	})
+}
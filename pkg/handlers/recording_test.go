@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shogotsuneto/jwks-mock-api/internal/recording"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/config"
+)
+
+func TestRecordRequests(t *testing.T) {
+	h := newTestHandler(t, &config.Config{
+		JWT:       config.JWTConfig{Issuer: testIssuer, Audience: "dev-api", Profile: config.ProfileJWT},
+		Recording: config.RecordingConfig{Capacity: 10, MaxBodyBytes: 16},
+	})
+	introspect := h.RecordRequests(http.HandlerFunc(h.Introspect))
+
+	valid, _, err := h.issueToken(jwt.MapClaims{"sub": "user"}, 300)
+	if err != nil {
+		t.Fatalf("failed to issue token: %v", err)
+	}
+	expired, _, err := h.issueToken(jwt.MapClaims{"sub": "user"}, -60)
+	if err != nil {
+		t.Fatalf("failed to issue token: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		token        string
+		wantDecision string
+		wantReason   string
+	}{
+		{"active", valid, recording.DecisionActive, ""},
+		{"expired", expired, recording.DecisionInactive, reasonExpired},
+		{"missing", "", recording.DecisionInactive, reasonMissingToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h.recordings.Clear()
+			body := url.Values{"token": {tt.token}}.Encode()
+			req := httptest.NewRequest("POST", "/introspect?trace=1", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rec := httptest.NewRecorder()
+			introspect.ServeHTTP(rec, req)
+
+			// The handler still reads the whole body
+			var response IntrospectionResponse
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode introspection response: %v", err)
+			}
+			if response.Active != (tt.wantDecision == recording.DecisionActive) {
+				t.Fatalf("Introspect() active = %v, want decision %s", response.Active, tt.wantDecision)
+			}
+
+			entries := h.recordings.List(recording.Filter{})
+			if len(entries) != 1 {
+				t.Fatalf("recorded %d requests, want 1", len(entries))
+			}
+			entry := entries[0]
+			if entry.Method != "POST" || entry.Path != "/introspect" || entry.Query != "trace=1" {
+				t.Errorf("recorded %s %s?%s, want POST /introspect?trace=1", entry.Method, entry.Path, entry.Query)
+			}
+			if entry.Headers.Get("Content-Type") != "application/x-www-form-urlencoded" {
+				t.Errorf("recorded Content-Type = %q", entry.Headers.Get("Content-Type"))
+			}
+			if entry.Body != body[:min(len(body), 16)] || entry.BodyTruncated != (len(body) > 16) {
+				t.Errorf("recorded body = %q (truncated %v), want the first 16 bytes of %q", entry.Body, entry.BodyTruncated, body)
+			}
+			if entry.Response.Status != http.StatusOK || entry.Response.Headers.Get("Content-Type") != "application/json" {
+				t.Errorf("recorded response status = %d, Content-Type = %q", entry.Response.Status, entry.Response.Headers.Get("Content-Type"))
+			}
+			if !entry.Response.BodyTruncated || len(entry.Response.Body) != 16 {
+				t.Errorf("recorded response body = %q (truncated %v), want 16 bytes", entry.Response.Body, entry.Response.BodyTruncated)
+			}
+			if entry.Token != tt.token || entry.Decision != tt.wantDecision || entry.Reason != tt.wantReason {
+				t.Errorf("recorded token decision = %s (%s), want %s (%s)", entry.Decision, entry.Reason, tt.wantDecision, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestRecordedRequests(t *testing.T) {
+	h := newTestHandler(t, &config.Config{
+		JWT:       config.JWTConfig{Issuer: testIssuer, Audience: "dev-api"},
+		Recording: config.RecordingConfig{Capacity: 10, MaxBodyBytes: 1024},
+	})
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/jwks.json", h.JWKS)
+	mux.HandleFunc("GET "+RecordedRequestsPath, h.RecordedRequests)
+	mux.HandleFunc("DELETE "+RecordedRequestsPath, h.ClearRecordedRequests)
+	handler := h.RecordRequests(mux)
+
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		return rec
+	}
+	serve("GET", "/.well-known/jwks.json")
+	serve("GET", "/.well-known/jwks.json")
+	serve("GET", "/missing")
+
+	tests := []struct {
+		query      string
+		wantStatus int
+		wantTotal  int
+	}{
+		{"", http.StatusOK, 3},
+		{"?path=/.well-known/jwks.json", http.StatusOK, 2},
+		{"?status=404", http.StatusOK, 1},
+		{"?method=POST", http.StatusOK, 0},
+		{"?since=1&limit=1", http.StatusOK, 1},
+		{"?limit=many", http.StatusBadRequest, 0},
+		{"?status=-1", http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rec := serve("GET", RecordedRequestsPath+tt.query)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("RecordedRequests() status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var response RecordedRequestsResponse
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if response.TotalRequests != tt.wantTotal || len(response.Requests) != tt.wantTotal || response.Capacity != 10 {
+				t.Errorf("RecordedRequests() total = %d, capacity = %d, want %d requests", response.TotalRequests, response.Capacity, tt.wantTotal)
+			}
+		})
+	}
+
+	// The recording endpoint does not record itself
+	rec := serve("DELETE", RecordedRequestsPath)
+	body, _ := io.ReadAll(rec.Body)
+	var cleared ClearRecordedRequestsResponse
+	if err := json.Unmarshal(body, &cleared); err != nil || !cleared.Success || cleared.Cleared != 3 {
+		t.Errorf("ClearRecordedRequests() = %s, want 3 cleared", body)
+	}
+}
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/shogotsuneto/jwks-mock-api/internal/clients"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/config"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/logger"
+)
+
+// ClientInformationResponse represents a client information response (RFC 7591 section 3.2.1, RFC 7592 section 3)
+type ClientInformationResponse struct {
+	config.ClientConfig
+	ClientIDIssuedAt        int64  `json:"client_id_issued_at"`
+	ClientSecretExpiresAt   int64  `json:"client_secret_expires_at"`
+	RegistrationAccessToken string `json:"registration_access_token"`
+	RegistrationClientURI   string `json:"registration_client_uri"`
+}
+
+// metadataError is returned when client metadata fails validation (RFC 7591 section 3.2.2)
+type metadataError struct {
+	code        string
+	description string
+}
+
+func (e *metadataError) Error() string {
+	return e.description
+}
+
+// validateClientMetadata checks registration metadata and fills in the RFC 7591 defaults
+func validateClientMetadata(client *config.ClientConfig) *metadataError {
+	if client.TokenEndpointAuthMethod == "" {
+		client.TokenEndpointAuthMethod = clients.AuthMethodClientSecretBasic
+	}
+	if !containsString(tokenEndpointAuthMethods, client.TokenEndpointAuthMethod) {
+		return &metadataError{"invalid_client_metadata", "Unsupported token_endpoint_auth_method: " + client.TokenEndpointAuthMethod}
+	}
+
+	if len(client.GrantTypes) == 0 {
+		client.GrantTypes = []string{GrantTypeAuthorizationCode}
+	}
+	for _, grantType := range client.GrantTypes {
+		if !containsString(supportedGrantTypes, grantType) {
+			return &metadataError{"invalid_client_metadata", "Unsupported grant type: " + grantType}
+		}
+	}
+
+	if len(client.ResponseTypes) == 0 && containsString(client.GrantTypes, GrantTypeAuthorizationCode) {
+		client.ResponseTypes = []string{"code"}
+	}
+	for _, responseType := range client.ResponseTypes {
+		if responseType != "code" {
+			return &metadataError{"invalid_client_metadata", "Unsupported response type: " + responseType}
+		}
+	}
+
+	if containsString(client.GrantTypes, GrantTypeAuthorizationCode) && len(client.RedirectURIs) == 0 {
+		return &metadataError{"invalid_redirect_uri", "redirect_uris are required for the authorization_code grant"}
+	}
+	for _, redirectURI := range client.RedirectURIs {
+		parsed, err := url.Parse(redirectURI)
+		if err != nil || !parsed.IsAbs() || parsed.Fragment != "" {
+			return &metadataError{"invalid_redirect_uri", "Redirect URIs must be absolute URLs without a fragment: " + redirectURI}
+		}
+	}
+
+	for _, redirectURI := range client.PostLogoutRedirectURIs {
+		if parsed, err := url.Parse(redirectURI); err != nil || !parsed.IsAbs() || parsed.Fragment != "" {
+			return &metadataError{"invalid_redirect_uri", "Post-logout redirect URIs must be absolute URLs without a fragment: " + redirectURI}
+		}
+	}
+	for _, requestURI := range client.RequestURIs {
+		if parsed, err := url.Parse(requestURI); err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+			return &metadataError{"invalid_client_metadata", "Request URIs must be http(s) URLs: " + requestURI}
+		}
+	}
+	for _, logoutURI := range []string{client.BackchannelLogoutURI, client.FrontchannelLogoutURI} {
+		if parsed, err := url.Parse(logoutURI); logoutURI != "" && (err != nil || !parsed.IsAbs()) {
+			return &metadataError{"invalid_client_metadata", "Logout URIs must be absolute URLs: " + logoutURI}
+		}
+	}
+
+	if len(client.JWKS) > 0 && client.JWKSURI != "" {
+		return &metadataError{"invalid_client_metadata", "jwks and jwks_uri cannot both be registered"}
+	}
+	if client.TokenEndpointAuthMethod == clients.AuthMethodPrivateKeyJWT && len(client.JWKS) == 0 && client.JWKSURI == "" {
+		return &metadataError{"invalid_client_metadata", "private_key_jwt requires jwks or jwks_uri"}
+	}
+	if client.TokenEndpointAuthMethod == clients.AuthMethodTLSClientAuth && !hasTLSClientAuth(client) {
+		return &metadataError{"invalid_client_metadata", "tls_client_auth requires tls_client_auth_subject_dn or tls_client_certificate_thumbprint"}
+	}
+
+	return nil
+}
+
+// checkFetchHosts rejects the URIs the server would request for the client unless they point
+// at one of the configured fetch hosts, so anonymous registration cannot make the server
+// send requests to arbitrary, e.g. internal, hosts
+func checkFetchHosts(client *config.ClientConfig, fetchHosts []string) *metadataError {
+	fetched := append([]string{client.JWKSURI, client.BackchannelLogoutURI}, client.RequestURIs...)
+	for _, uri := range fetched {
+		if uri == "" {
+			continue
+		}
+		parsed, err := url.Parse(uri)
+		allowed := false
+		for _, host := range fetchHosts {
+			allowed = allowed || (err == nil && config.HostName(host) == strings.ToLower(parsed.Hostname()))
+		}
+		if !allowed {
+			return &metadataError{"invalid_client_metadata", "jwks_uri, request_uris and backchannel_logout_uri must point at a host in registration.fetch_hosts: " + uri}
+		}
+	}
+	return nil
+}
+
+// decodeClientMetadata reads and validates the client metadata in a request body
+func (h *Handler) decodeClientMetadata(r *http.Request) (config.ClientConfig, *metadataError) {
+	var client config.ClientConfig
+	if err := json.NewDecoder(r.Body).Decode(&client); err != nil {
+		return client, &metadataError{"invalid_client_metadata", fmt.Sprintf("Invalid JSON: %v", err)}
+	}
+	if err := validateClientMetadata(&client); err != nil {
+		return client, err
+	}
+	if err := checkFetchHosts(&client, h.config.Registration.FetchHosts); err != nil {
+		return client, err
+	}
+	return client, nil
+}
+
+// writeClientInformation writes a client information response
+func (h *Handler) writeClientInformation(w http.ResponseWriter, status int, registration *clients.Registration) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ClientInformationResponse{
+		ClientConfig:            registration.Client,
+		ClientIDIssuedAt:        registration.IssuedAt.Unix(),
+		ClientSecretExpiresAt:   0, // secrets never expire
+		RegistrationAccessToken: registration.RegistrationAccessToken,
+		RegistrationClientURI:   h.endpointURL("/register/" + url.PathEscape(registration.Client.ClientID)),
+	})
+}
+
+// writeRegistrationAuthError answers a management request whose registration access token is
+// missing or wrong, or whose client does not exist
+func writeRegistrationAuthError(ctx context.Context, w http.ResponseWriter, err error) {
+	if errors.Is(err, clients.ErrInvalidAccessToken) {
+		writeBearerError(w, http.StatusUnauthorized, "invalid_token", "The registration access token is invalid for this client")
+		return
+	}
+	logger.ErrorContext(ctx, "Error managing client registration", "error", err)
+	writeOAuthError(w, http.StatusInternalServerError, "server_error", "Failed to update client registration")
+}
+
+// RegisterClient implements the dynamic client registration endpoint (RFC 7591)
+func (h *Handler) RegisterClient(w http.ResponseWriter, r *http.Request) {
+	client, metaErr := h.decodeClientMetadata(r)
+	if metaErr != nil {
+		writeOAuthError(w, http.StatusBadRequest, metaErr.code, metaErr.description)
+		return
+	}
+
+	registration, err := h.clients.Register(client)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Error registering client", "error", err)
+		writeOAuthError(w, http.StatusInternalServerError, "server_error", "Failed to register client")
+		return
+	}
+
+	logger.InfoContext(r.Context(), "Registered client", "client_id", registration.Client.ClientID)
+	h.writeClientInformation(w, http.StatusCreated, registration)
+}
+
+// GetClientRegistration implements the client read request (RFC 7592 section 2.1)
+func (h *Handler) GetClientRegistration(w http.ResponseWriter, r *http.Request) {
+	registration, err := h.clients.Lookup(mux.Vars(r)["client_id"], bearerToken(r))
+	if err != nil {
+		writeRegistrationAuthError(r.Context(), w, err)
+		return
+	}
+
+	h.writeClientInformation(w, http.StatusOK, registration)
+}
+
+// UpdateClientRegistration implements the client update request (RFC 7592 section 2.2)
+func (h *Handler) UpdateClientRegistration(w http.ResponseWriter, r *http.Request) {
+	clientID := mux.Vars(r)["client_id"]
+	current, err := h.clients.Lookup(clientID, bearerToken(r))
+	if err != nil {
+		writeRegistrationAuthError(r.Context(), w, err)
+		return
+	}
+
+	client, metaErr := h.decodeClientMetadata(r)
+	if metaErr != nil {
+		writeOAuthError(w, http.StatusBadRequest, metaErr.code, metaErr.description)
+		return
+	}
+	if client.ClientID != clientID {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "The client_id must match the client being updated")
+		return
+	}
+	if client.ClientSecret != "" && !secureCompare(client.ClientSecret, current.Client.ClientSecret) {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "The client_secret does not match the registered secret")
+		return
+	}
+
+	registration, err := h.clients.Update(clientID, bearerToken(r), client)
+	if err != nil {
+		writeRegistrationAuthError(r.Context(), w, err)
+		return
+	}
+
+	h.writeClientInformation(w, http.StatusOK, registration)
+}
+
+// DeleteClientRegistration implements the client delete request (RFC 7592 section 2.3)
+func (h *Handler) DeleteClientRegistration(w http.ResponseWriter, r *http.Request) {
+	clientID := mux.Vars(r)["client_id"]
+	if err := h.clients.Delete(clientID, bearerToken(r)); err != nil {
+		writeRegistrationAuthError(r.Context(), w, err)
+		return
+	}
+
+	logger.InfoContext(r.Context(), "Deleted client registration", "client_id", clientID)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/shogotsuneto/jwks-mock-api/internal/revocation"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/logger"
+)
+
+// RevokedTokensResponse represents the revocation store listing
+type RevokedTokensResponse struct {
+	TotalRevoked  int                `json:"total_revoked"`
+	RevokedTokens []revocation.Entry `json:"revoked_tokens"`
+}
+
+// ClearRevokedTokensResponse represents the response for clearing the revocation store
+type ClearRevokedTokensResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Cleared int    `json:"cleared"`
+}
+
+// Revoke implements OAuth 2.0 Token Revocation (RFC 7009)
+func (h *Handler) Revoke(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "Malformed form body")
+		return
+	}
+
+	token := r.PostFormValue("token")
+	if token == "" {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "The token parameter is required")
+		return
+	}
+
+	// RFC 7009: invalid tokens do not cause an error response, there is simply nothing to revoke.
+	// token_type_hint is accepted but not needed since all tokens are self-contained JWTs.
+	claims, err := h.validateToken(token)
+	if err != nil {
+		logger.DebugContext(r.Context(), "Ignoring revocation of inactive token", "error", err)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	logTokenFields(r, tokenKeyID(token), claims)
+
+	jti, _ := claims["jti"].(string)
+	sub, _ := claims["sub"].(string)
+	var expiresAt time.Time
+	if exp, ok := claims["exp"].(float64); ok {
+		expiresAt = time.Unix(int64(exp), 0).UTC()
+	}
+
+	entry := h.revocations.Revoke(token, jti, sub, expiresAt)
+	logger.DebugContext(r.Context(), "Revoked token", "kind", entry.Kind, "value", entry.Value)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// RevokedTokens handles GET /revoked-tokens to list the revocation store
+func (h *Handler) RevokedTokens(w http.ResponseWriter, r *http.Request) {
+	entries := h.revocations.List()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(RevokedTokensResponse{
+		TotalRevoked:  len(entries),
+		RevokedTokens: entries,
+	})
+}
+
+// ClearRevokedTokens handles DELETE /revoked-tokens to clear the revocation store
+func (h *Handler) ClearRevokedTokens(w http.ResponseWriter, r *http.Request) {
+	cleared := h.revocations.Clear()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ClearRevokedTokensResponse{
+		Success: true,
+		Message: "Revocation store cleared",
+		Cleared: cleared,
+	})
+}
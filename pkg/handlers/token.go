@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/config"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/logger"
+)
+
+// Grant types supported by the token endpoint
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeTokenExchange     = "urn:ietf:params:oauth:grant-type:token-exchange"
+	GrantTypeDeviceCode        = "urn:ietf:params:oauth:grant-type:device_code"
+	GrantTypePassword          = "password"
+)
+
+// supportedGrantTypes lists the grant types accepted by the token endpoint
+var supportedGrantTypes = []string{GrantTypeAuthorizationCode, GrantTypeTokenExchange, GrantTypeDeviceCode, GrantTypePassword}
+
+// defaultExpiresIn is the lifetime in seconds of tokens issued by the token endpoint
+const defaultExpiresIn = 3600
+
+// Token implements the OAuth 2.0 token endpoint, dispatching on grant_type
+func (h *Handler) Token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "Malformed form body")
+		return
+	}
+
+	r, ok := h.checkDPoPProof(w, r)
+	if !ok {
+		return
+	}
+
+	grantType := r.PostFormValue("grant_type")
+	if containsString(supportedGrantTypes, grantType) && !h.checkProfileClient(w, r) {
+		return
+	}
+
+	switch grantType {
+	case GrantTypeAuthorizationCode:
+		h.authorizationCodeGrant(w, r)
+	case GrantTypeTokenExchange:
+		if h.checkRegisteredClient(w, r, grantType) {
+			h.tokenExchange(w, r)
+		}
+	case GrantTypeDeviceCode:
+		if h.checkRegisteredClient(w, r, grantType) {
+			h.deviceCodeGrant(w, r)
+		}
+	case GrantTypePassword:
+		if h.checkRegisteredClient(w, r, grantType) {
+			h.passwordGrant(w, r)
+		}
+	case "":
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "The grant_type parameter is required")
+	default:
+		writeOAuthError(w, http.StatusBadRequest, "unsupported_grant_type", "Unsupported grant_type: "+grantType)
+	}
+}
+
+// checkRegisteredClient authenticates the client of a token request when it is in the client
+// registry and checks it may use the grant type, writing an error response otherwise.
+// Unregistered client IDs are accepted as is so ad hoc clients keep working.
+func (h *Handler) checkRegisteredClient(w http.ResponseWriter, r *http.Request, grantType string) bool {
+	if _, registered := h.clients.Get(requestClientID(r)); !registered {
+		return true
+	}
+
+	client, authErr := h.authenticateClient(r)
+	if authErr != nil {
+		writeClientAuthError(w, authErr)
+		return false
+	}
+	if !clientAllowsGrant(client, grantType) {
+		writeOAuthError(w, http.StatusBadRequest, "unauthorized_client", "The client is not registered for grant_type "+grantType)
+		return false
+	}
+	return true
+}
+
+// requestClientID returns the client ID a token request was made with, from HTTP basic
+// auth, the client_id form parameter or the subject of a client assertion
+func requestClientID(r *http.Request) string {
+	if clientID, _, ok := r.BasicAuth(); ok {
+		return clientID
+	}
+	if clientID := r.PostFormValue("client_id"); clientID != "" {
+		return clientID
+	}
+	return assertionClientID(r.PostFormValue("client_assertion"))
+}
+
+// writeUserTokenResponse issues an access token for a configured user, plus an ID token
+// when the openid scope was granted, and writes the token endpoint response
+func (h *Handler) writeUserTokenResponse(w http.ResponseWriter, r *http.Request, user *config.UserConfig, clientID, scope, nonce string) {
+	scope = h.withoutAdminScope(scope)
+	claims := jwt.MapClaims{
+		"sub": user.Sub,
+		"aud": h.accessTokenAudience(r),
+		"jti": newRandomID(),
+	}
+	if clientID != "" {
+		claims["client_id"] = clientID
+	}
+	if scope != "" {
+		claims["scope"] = scope
+	}
+	if len(user.Roles) > 0 {
+		claims["roles"] = user.Roles
+	}
+	if len(user.Groups) > 0 {
+		claims["groups"] = user.Groups
+	}
+	tokenType := bindAccessToken(r, claims)
+
+	accessToken, keyPair, err := h.issueToken(claims, defaultExpiresIn)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Error issuing access token", "error", err)
+		writeOAuthError(w, http.StatusInternalServerError, "server_error", "Failed to issue token")
+		return
+	}
+	logTokenFields(r, keyPair.Kid, claims)
+
+	response := OAuthTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   tokenType,
+		ExpiresIn:   defaultExpiresIn,
+		Scope:       scope,
+	}
+
+	if clientID != "" && containsScope(scope, "openid") {
+		// The ID token's sid names the session that logout ends
+		sid, err := h.sessions.Join(user.Sub, clientID)
+		if err != nil {
+			logger.ErrorContext(r.Context(), "Error starting session", "error", err)
+			writeOAuthError(w, http.StatusInternalServerError, "server_error", "Failed to start session")
+			return
+		}
+
+		idToken, err := h.issueIDToken(jwt.MapClaims{"sub": user.Sub, "sid": sid}, defaultExpiresIn, &IDTokenOptions{
+			ClientID:    clientID,
+			Nonce:       nonce,
+			AccessToken: accessToken,
+		})
+		if err != nil {
+			logger.ErrorContext(r.Context(), "Error issuing ID token", "error", err)
+			writeOAuthError(w, http.StatusInternalServerError, "server_error", "Failed to issue ID token")
+			return
+		}
+		response.IDToken = idToken
+	}
+
+	writeTokenResponse(w, response)
+}
+
+// containsScope reports whether a space-delimited scope string contains the given scope
+func containsScope(scope, want string) bool {
+	for _, s := range strings.Fields(scope) {
+		if s == want {
+			return true
+		}
+	}
+	return false
+}
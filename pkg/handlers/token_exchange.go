@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/logger"
+)
+
+// Token type identifiers (RFC 8693 section 3)
+const (
+	TokenTypeAccessToken = "urn:ietf:params:oauth:token-type:access_token"
+	TokenTypeJWT         = "urn:ietf:params:oauth:token-type:jwt"
+	TokenTypeIDToken     = "urn:ietf:params:oauth:token-type:id_token"
+)
+
+// exchangeExcludedClaims are subject token claims that describe the token itself
+// rather than the subject, and are therefore not carried over to the issued token
+var exchangeExcludedClaims = map[string]bool{
+	"iss": true, "aud": true, "exp": true, "iat": true, "nbf": true, "jti": true,
+	"scope": true, "scp": true, "act": true, "may_act": true, "cnf": true,
+	"client_id": true, "azp": true, "nonce": true, "at_hash": true, "c_hash": true,
+}
+
+// isSupportedTokenType reports whether a subject or actor token type can be validated
+func isSupportedTokenType(tokenType string) bool {
+	switch tokenType {
+	case TokenTypeAccessToken, TokenTypeJWT, TokenTypeIDToken:
+		return true
+	default:
+		return false
+	}
+}
+
+// tokenExchange implements the OAuth 2.0 Token Exchange grant (RFC 8693)
+func (h *Handler) tokenExchange(w http.ResponseWriter, r *http.Request) {
+	subjectToken := r.PostFormValue("subject_token")
+	subjectTokenType := r.PostFormValue("subject_token_type")
+	if subjectToken == "" || subjectTokenType == "" {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "subject_token and subject_token_type are required")
+		return
+	}
+	if !isSupportedTokenType(subjectTokenType) {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "Unsupported subject_token_type: "+subjectTokenType)
+		return
+	}
+
+	issuedTokenType := TokenTypeAccessToken
+	switch requested := r.PostFormValue("requested_token_type"); requested {
+	case "", TokenTypeAccessToken:
+	case TokenTypeJWT:
+		issuedTokenType = TokenTypeJWT
+	default:
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "Unsupported requested_token_type: "+requested)
+		return
+	}
+
+	subjectClaims, err := h.validateToken(subjectToken)
+	if err != nil {
+		logger.DebugContext(r.Context(), "Token exchange subject token rejected", "error", err)
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "The subject_token is invalid")
+		return
+	}
+
+	// The optional actor token identifies the party acting on behalf of the subject
+	var actorClaims jwt.MapClaims
+	actorToken := r.PostFormValue("actor_token")
+	actorTokenType := r.PostFormValue("actor_token_type")
+	if actorToken != "" {
+		if !isSupportedTokenType(actorTokenType) {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_request", "A supported actor_token_type is required with actor_token")
+			return
+		}
+		actorClaims, err = h.validateToken(actorToken)
+		if err != nil {
+			logger.DebugContext(r.Context(), "Token exchange actor token rejected", "error", err)
+			writeOAuthError(w, http.StatusBadRequest, "invalid_request", "The actor_token is invalid")
+			return
+		}
+		if sub, _ := actorClaims["sub"].(string); sub == "" {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_request", "The actor_token has no sub claim")
+			return
+		}
+		if mayAct, ok := subjectClaims["may_act"].(map[string]interface{}); ok && mayAct["sub"] != actorClaims["sub"] {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_request", "The actor is not authorized by the subject token's may_act claim")
+			return
+		}
+	} else if actorTokenType != "" {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "actor_token_type must not be sent without actor_token")
+		return
+	}
+
+	scope, ok := narrowScope(tokenScopes(subjectClaims), strings.Fields(r.PostFormValue("scope")))
+	if !ok {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_scope", "The requested scope exceeds the scope of the subject_token")
+		return
+	}
+	scope = h.withoutAdminScope(scope)
+
+	claims := jwt.MapClaims{}
+	for key, value := range subjectClaims {
+		if !exchangeExcludedClaims[key] {
+			claims[key] = value
+		}
+	}
+
+	// Both audience and resource identify the target service
+	targets := append(append([]string{}, r.PostForm["audience"]...), r.PostForm["resource"]...)
+	switch len(targets) {
+	case 0:
+		claims["aud"] = h.config.JWT.Audience
+	case 1:
+		claims["aud"] = targets[0]
+	default:
+		claims["aud"] = targets
+	}
+
+	if scope != "" {
+		claims["scope"] = scope
+	}
+
+	// Record delegation: the current actor is the top-level act claim and any
+	// prior delegation chain from the subject token is nested below it
+	priorAct, hasPriorAct := subjectClaims["act"]
+	if actorClaims != nil {
+		act := map[string]interface{}{"sub": actorClaims["sub"]}
+		if hasPriorAct {
+			act["act"] = priorAct
+		}
+		claims["act"] = act
+	} else if hasPriorAct {
+		claims["act"] = priorAct
+	}
+
+	if clientID := requestClientID(r); clientID != "" {
+		claims["client_id"] = clientID
+	}
+	claims["jti"] = newRandomID()
+	tokenType := bindAccessToken(r, claims)
+
+	tokenString, keyPair, err := h.issueToken(claims, defaultExpiresIn)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Error issuing exchanged token", "error", err)
+		writeOAuthError(w, http.StatusInternalServerError, "server_error", "Failed to issue token")
+		return
+	}
+	logTokenFields(r, keyPair.Kid, claims)
+
+	writeTokenResponse(w, OAuthTokenResponse{
+		AccessToken:     tokenString,
+		IssuedTokenType: issuedTokenType,
+		TokenType:       tokenType,
+		ExpiresIn:       defaultExpiresIn,
+		Scope:           scope,
+	})
+}
+
+// narrowScope returns the scope for an exchanged token. Without a request the subject's
+// scope is kept; a requested scope must be a subset of the subject's scope, if it has one.
+func narrowScope(subjectScopes, requestedScopes []string) (string, bool) {
+	if len(requestedScopes) == 0 {
+		return strings.Join(subjectScopes, " "), true
+	}
+	if len(subjectScopes) == 0 {
+		return strings.Join(requestedScopes, " "), true
+	}
+
+	granted := make(map[string]bool)
+	for _, scope := range subjectScopes {
+		granted[scope] = true
+	}
+	for _, scope := range requestedScopes {
+		if !granted[scope] {
+			return "", false
+		}
+	}
+	return strings.Join(requestedScopes, " "), true
+}
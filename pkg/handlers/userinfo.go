@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shogotsuneto/jwks-mock-api/pkg/logger"
+)
+
+// scopeClaims maps the OpenID Connect standard scopes (plus roles and groups) to the claims they release
+var scopeClaims = map[string][]string{
+	"profile": {
+		"name", "family_name", "given_name", "middle_name", "nickname",
+		"preferred_username", "profile", "picture", "website", "gender",
+		"birthdate", "zoneinfo", "locale", "updated_at",
+	},
+	"email":   {"email", "email_verified"},
+	"address": {"address"},
+	"phone":   {"phone_number", "phone_number_verified"},
+	"roles":   {"roles"},
+	"groups":  {"groups"},
+}
+
+// claimScopes is the reverse lookup of scopeClaims
+var claimScopes = func() map[string]string {
+	result := make(map[string]string)
+	for scope, claims := range scopeClaims {
+		for _, claim := range claims {
+			result[claim] = scope
+		}
+	}
+	return result
+}()
+
+// bearerToken extracts a bearer token from the Authorization header or,
+// for form-encoded POST requests, the access_token parameter (RFC 6750)
+func bearerToken(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		if len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+			return strings.TrimSpace(auth[7:])
+		}
+		return ""
+	}
+
+	if r.Method == http.MethodPost && strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		return r.PostFormValue("access_token")
+	}
+
+	return ""
+}
+
+// tokenScopes returns the scopes granted to a token from its scope (space-delimited) or scp (array) claim
+func tokenScopes(claims jwt.MapClaims) []string {
+	if scope, ok := claims["scope"].(string); ok {
+		return strings.Fields(scope)
+	}
+
+	var scopes []string
+	if scp, ok := claims["scp"].([]interface{}); ok {
+		for _, s := range scp {
+			if str, ok := s.(string); ok {
+				scopes = append(scopes, str)
+			}
+		}
+	}
+	return scopes
+}
+
+// writeBearerError writes an RFC 6750 error response with a WWW-Authenticate challenge
+func writeBearerError(w http.ResponseWriter, status int, errorCode, description string) {
+	challenge := "Bearer"
+	if errorCode != "" {
+		challenge = fmt.Sprintf(`Bearer error="%s", error_description="%s"`, errorCode, description)
+	}
+
+	w.Header().Set("WWW-Authenticate", challenge)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	response := map[string]string{}
+	if errorCode != "" {
+		response["error"] = errorCode
+		response["error_description"] = description
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// UserInfo implements the OpenID Connect UserInfo endpoint
+func (h *Handler) UserInfo(w http.ResponseWriter, r *http.Request) {
+	token := bearerToken(r)
+	if token == "" {
+		writeBearerError(w, http.StatusUnauthorized, "", "")
+		return
+	}
+
+	claims, err := h.validateToken(token)
+	if err != nil {
+		logger.DebugContext(r.Context(), "UserInfo token rejected", "error", err)
+		recordTokenDecision(r, token, inactiveReason(err))
+		writeBearerError(w, http.StatusUnauthorized, "invalid_token", "The access token is invalid")
+		return
+	}
+	logTokenFields(r, tokenKeyID(token), claims)
+	recordTokenDecision(r, token, "")
+
+	granted := make(map[string]bool)
+	for _, scope := range tokenScopes(claims) {
+		granted[scope] = true
+	}
+	if !granted["openid"] {
+		writeBearerError(w, http.StatusForbidden, "insufficient_scope", "The access token does not grant the openid scope")
+		return
+	}
+
+	sub, _ := claims["sub"].(string)
+	user := h.config.FindUser(sub)
+	if user == nil {
+		writeBearerError(w, http.StatusUnauthorized, "invalid_token", "No user matches the token subject")
+		return
+	}
+
+	response := map[string]interface{}{"sub": user.Sub}
+
+	// Claims tied to a scope are released only when that scope was granted;
+	// custom claims not covered by any scope are always released
+	for key, value := range user.Claims {
+		if scope, ok := claimScopes[key]; ok && !granted[scope] {
+			continue
+		}
+		if key == "sub" {
+			continue
+		}
+		response[key] = value
+	}
+	if granted["roles"] && len(user.Roles) > 0 {
+		response["roles"] = user.Roles
+	}
+	if granted["groups"] && len(user.Groups) > 0 {
+		response["groups"] = user.Groups
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
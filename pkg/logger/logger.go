@@ -1,9 +1,15 @@
 package logger
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"log"
+	"log/slog"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 )
 
 // LogLevel represents different logging levels
@@ -16,17 +22,84 @@ const (
 	ERROR
 )
 
+// Output formats for Options.Format
+const (
+	FormatText = "text" // classic "[INFO] message" lines, the default
+	FormatJSON = "json" // one JSON object per line, written through log/slog
+)
+
+// levelFatal is the slog level of Fatal messages, shown as FATAL in JSON output
+const levelFatal = slog.Level(12)
+
+// Options configures the default logger
+type Options struct {
+	Level  string
+	Format string
+	Output io.Writer // defaults to the output of the standard log package
+}
+
 // Logger wraps the standard log package with level support
 type Logger struct {
 	level LogLevel
+	json  *slog.Logger // set when logging JSON
 }
 
 var defaultLogger *Logger
 
 // Init initializes the default logger with the specified level
 func Init(levelStr string) {
-	level := parseLogLevel(levelStr)
-	defaultLogger = &Logger{level: level}
+	Setup(Options{Level: levelStr})
+}
+
+// Setup initializes the default logger with the given level, format and output
+func Setup(opts Options) {
+	output := opts.Output
+	if output != nil {
+		log.SetOutput(output)
+	} else {
+		output = log.Writer()
+	}
+
+	l := &Logger{level: parseLogLevel(opts.Level)}
+	if strings.ToLower(opts.Format) == FormatJSON {
+		l.json = newJSONLogger(output)
+	}
+	defaultLogger = l
+}
+
+// newJSONLogger creates a slog logger writing JSON lines to w. Levels are filtered by
+// shouldLog, so the handler lets everything through.
+func newJSONLogger(w io.Writer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       slog.LevelDebug,
+		ReplaceAttr: replaceLevel,
+	}))
+}
+
+// replaceLevel names the fatal level in JSON output
+func replaceLevel(groups []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.LevelKey && len(groups) == 0 {
+		if level, ok := a.Value.Any().(slog.Level); ok && level == levelFatal {
+			a.Value = slog.StringValue("FATAL")
+		}
+	}
+	return a
+}
+
+// OpenOutput opens a log destination: "stdout", "stderr" or the path of a file to append to
+func OpenOutput(dest string) (*os.File, error) {
+	switch strings.ToLower(dest) {
+	case "", "stderr":
+		return os.Stderr, nil
+	case "stdout":
+		return os.Stdout, nil
+	}
+
+	file, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log output: %w", err)
+	}
+	return file, nil
 }
 
 // parseLogLevel converts a string to LogLevel
@@ -50,32 +123,90 @@ func (l *Logger) shouldLog(level LogLevel) bool {
 	return level >= l.level
 }
 
+// slogLevel converts a LogLevel to its slog equivalent
+func (level LogLevel) slogLevel() slog.Level {
+	switch level {
+	case DEBUG:
+		return slog.LevelDebug
+	case WARN:
+		return slog.LevelWarn
+	case ERROR:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// tag returns the prefix of text output for the level
+func (level LogLevel) tag() string {
+	switch level {
+	case DEBUG:
+		return "[DEBUG] "
+	case WARN:
+		return "[WARN] "
+	case ERROR:
+		return "[ERROR] "
+	default:
+		return "[INFO] "
+	}
+}
+
+// write logs a message at the given level, with the fields of the request in ctx and any
+// key-value pairs in args
+func write(ctx context.Context, level LogLevel, msg string, args ...any) {
+	if defaultLogger == nil || !defaultLogger.shouldLog(level) {
+		return
+	}
+
+	attrs := append(contextAttrs(ctx), slog.Group("", args...).Value.Group()...)
+	if defaultLogger.json != nil {
+		defaultLogger.json.LogAttrs(ctx, level.slogLevel(), msg, attrs...)
+		return
+	}
+	log.Print(level.tag() + msg + formatAttrs(attrs))
+}
+
+// formatAttrs renders attributes as " key=value" pairs for text output
+func formatAttrs(attrs []slog.Attr) string {
+	var b strings.Builder
+	for _, a := range attrs {
+		value := a.Value.Resolve().String()
+		if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+			value = strconv.Quote(value)
+		}
+		fmt.Fprintf(&b, " %s=%s", a.Key, value)
+	}
+	return b.String()
+}
+
 // Debug logs a debug message
 func Debug(v ...interface{}) {
-	if defaultLogger != nil && defaultLogger.shouldLog(DEBUG) {
-		log.Print(append([]interface{}{"[DEBUG] "}, v...)...)
-	}
+	write(context.Background(), DEBUG, fmt.Sprint(v...))
 }
 
 // Debugf logs a formatted debug message
 func Debugf(format string, v ...interface{}) {
-	if defaultLogger != nil && defaultLogger.shouldLog(DEBUG) {
-		log.Printf("[DEBUG] "+format, v...)
-	}
+	write(context.Background(), DEBUG, fmt.Sprintf(format, v...))
+}
+
+// DebugContext logs a debug message with the request fields in ctx and the key-value pairs in args
+func DebugContext(ctx context.Context, msg string, args ...any) {
+	write(ctx, DEBUG, msg, args...)
 }
 
 // Info logs an info message
 func Info(v ...interface{}) {
-	if defaultLogger != nil && defaultLogger.shouldLog(INFO) {
-		log.Print(append([]interface{}{"[INFO] "}, v...)...)
-	}
+	write(context.Background(), INFO, fmt.Sprint(v...))
 }
 
 // Infof logs a formatted info message
 func Infof(format string, v ...interface{}) {
-	if defaultLogger != nil && defaultLogger.shouldLog(INFO) {
-		log.Printf("[INFO] "+format, v...)
-	}
+	write(context.Background(), INFO, fmt.Sprintf(format, v...))
+}
+
+// InfoContext logs an info message with the request fields in ctx and the key-value pairs in args
+func InfoContext(ctx context.Context, msg string, args ...any) {
+	write(ctx, INFO, msg, args...)
 }
 
 // Printf is an alias for Infof to maintain compatibility with existing log.Printf calls
@@ -95,43 +226,118 @@ func Println(v ...interface{}) {
 
 // Warn logs a warning message
 func Warn(v ...interface{}) {
-	if defaultLogger != nil && defaultLogger.shouldLog(WARN) {
-		log.Print(append([]interface{}{"[WARN] "}, v...)...)
-	}
+	write(context.Background(), WARN, fmt.Sprint(v...))
 }
 
 // Warnf logs a formatted warning message
 func Warnf(format string, v ...interface{}) {
-	if defaultLogger != nil && defaultLogger.shouldLog(WARN) {
-		log.Printf("[WARN] "+format, v...)
-	}
+	write(context.Background(), WARN, fmt.Sprintf(format, v...))
+}
+
+// WarnContext logs a warning message with the request fields in ctx and the key-value pairs in args
+func WarnContext(ctx context.Context, msg string, args ...any) {
+	write(ctx, WARN, msg, args...)
 }
 
 // Error logs an error message
 func Error(v ...interface{}) {
-	if defaultLogger != nil && defaultLogger.shouldLog(ERROR) {
-		log.Print(append([]interface{}{"[ERROR] "}, v...)...)
-	}
+	write(context.Background(), ERROR, fmt.Sprint(v...))
 }
 
 // Errorf logs a formatted error message
 func Errorf(format string, v ...interface{}) {
-	if defaultLogger != nil && defaultLogger.shouldLog(ERROR) {
-		log.Printf("[ERROR] "+format, v...)
-	}
+	write(context.Background(), ERROR, fmt.Sprintf(format, v...))
+}
+
+// ErrorContext logs an error message with the request fields in ctx and the key-value pairs in args
+func ErrorContext(ctx context.Context, msg string, args ...any) {
+	write(ctx, ERROR, msg, args...)
 }
 
 // Fatal logs a fatal message and exits (always shown regardless of level)
 func Fatal(v ...interface{}) {
-	log.Fatal(append([]interface{}{"[FATAL] "}, v...)...)
+	fatal(fmt.Sprint(v...))
 }
 
 // Fatalf logs a formatted fatal message and exits (always shown regardless of level)
 func Fatalf(format string, v ...interface{}) {
-	log.Fatalf("[FATAL] "+format, v...)
+	fatal(fmt.Sprintf(format, v...))
+}
+
+// fatal logs the message in the configured format and exits
+func fatal(msg string) {
+	if defaultLogger != nil && defaultLogger.json != nil {
+		defaultLogger.json.Log(context.Background(), levelFatal, msg)
+		os.Exit(1)
+	}
+	log.Fatal("[FATAL] " + msg)
 }
 
 // SetOutput sets the output destination for the logger
 func SetOutput(file *os.File) {
 	log.SetOutput(file)
+	if defaultLogger != nil && defaultLogger.json != nil {
+		defaultLogger.json = newJSONLogger(file)
+	}
+}
+
+// requestFields collects the fields logged with every message about one request
+type requestFields struct {
+	mu        sync.Mutex // Protect concurrent access to attrs
+	requestID string
+	attrs     []slog.Attr
+}
+
+// fieldsKey is the context key of the request fields
+type fieldsKey struct{}
+
+// NewContext returns a context carrying the request ID, to which handlers can add fields
+// with AddFields
+func NewContext(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, fieldsKey{}, &requestFields{requestID: requestID})
+}
+
+// RequestID returns the request ID carried by ctx, or an empty string
+func RequestID(ctx context.Context) string {
+	if fields, ok := ctx.Value(fieldsKey{}).(*requestFields); ok {
+		return fields.requestID
+	}
+	return ""
+}
+
+// AddFields adds key-value pairs to the fields logged for the request in ctx, replacing
+// earlier values of the same keys. It does nothing for contexts without request fields.
+func AddFields(ctx context.Context, args ...any) {
+	fields, ok := ctx.Value(fieldsKey{}).(*requestFields)
+	if !ok {
+		return
+	}
+
+	fields.mu.Lock()
+	defer fields.mu.Unlock()
+	for _, attr := range slog.Group("", args...).Value.Group() {
+		replaced := false
+		for i := range fields.attrs {
+			if fields.attrs[i].Key == attr.Key {
+				fields.attrs[i] = attr
+				replaced = true
+			}
+		}
+		if !replaced {
+			fields.attrs = append(fields.attrs, attr)
+		}
+	}
+}
+
+// contextAttrs returns the request ID and fields carried by ctx
+func contextAttrs(ctx context.Context) []slog.Attr {
+	fields, ok := ctx.Value(fieldsKey{}).(*requestFields)
+	if !ok {
+		return nil
+	}
+
+	fields.mu.Lock()
+	defer fields.mu.Unlock()
+	attrs := []slog.Attr{slog.String("request_id", fields.requestID)}
+	return append(attrs, fields.attrs...)
 }
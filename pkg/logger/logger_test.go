@@ -2,8 +2,11 @@ package logger
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -117,3 +120,112 @@ func TestParseLogLevel(t *testing.T) {
 		})
 	}
 }
+
+func TestJSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	Setup(Options{Level: "info", Format: FormatJSON, Output: &buf})
+	defer func() {
+		log.SetOutput(os.Stderr)
+		Init("info")
+	}()
+
+	Debugf("hidden %d", 1)
+	if buf.Len() != 0 {
+		t.Errorf("Expected no log output below the level, got: %s", buf.String())
+	}
+
+	ctx := NewContext(context.Background(), "req-1")
+	AddFields(ctx, "kid", "key-1", "sub", "old")
+	AddFields(ctx, "sub", "alice")
+	InfoContext(ctx, "request completed", "status", 200)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Expected a JSON line, got %v: %s", err, buf.String())
+	}
+	want := map[string]interface{}{
+		"level":      "INFO",
+		"msg":        "request completed",
+		"request_id": "req-1",
+		"kid":        "key-1",
+		"sub":        "alice",
+		"status":     float64(200),
+	}
+	for key, value := range want {
+		if entry[key] != value {
+			t.Errorf("%s = %v, want %v", key, entry[key], value)
+		}
+	}
+}
+
+func TestTextFormatFields(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+	Init("info")
+
+	ctx := NewContext(context.Background(), "req-2")
+	InfoContext(ctx, "request completed", "path", "/jwks", "error", "bad token")
+
+	output := buf.String()
+	for _, want := range []string{"[INFO] request completed", "request_id=req-2", "path=/jwks", `error="bad token"`} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected log output to contain %s, got: %s", want, output)
+		}
+	}
+	WarnContext(ctx, "back-channel logout failed", "client_id", "app")
+	if output := buf.String(); !strings.Contains(output, "[WARN] back-channel logout failed request_id=req-2 client_id=app") {
+		t.Errorf("Expected a warning with the request fields, got: %s", output)
+	}
+	if RequestID(ctx) != "req-2" {
+		t.Errorf("RequestID() = %q, want %q", RequestID(ctx), "req-2")
+	}
+	if RequestID(context.Background()) != "" {
+		t.Errorf("RequestID() without request fields = %q, want empty", RequestID(context.Background()))
+	}
+}
+
+func TestOpenOutput(t *testing.T) {
+	tests := []struct {
+		dest    string
+		want    *os.File
+		wantErr bool
+	}{
+		{"", os.Stderr, false},
+		{"stderr", os.Stderr, false},
+		{"STDOUT", os.Stdout, false},
+		{filepath.Join(t.TempDir(), "missing", "app.log"), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dest, func(t *testing.T) {
+			got, err := OpenOutput(tt.dest)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OpenOutput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("OpenOutput(%q) = %v, want %v", tt.dest, got, tt.want)
+			}
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	file, err := OpenOutput(path)
+	if err != nil {
+		t.Fatalf("OpenOutput() error = %v", err)
+	}
+	defer file.Close()
+	Setup(Options{Level: "info", Format: FormatJSON, Output: file})
+	defer func() {
+		log.SetOutput(os.Stderr)
+		Init("info")
+	}()
+	Infof("to file")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(data), `"msg":"to file"`) {
+		t.Errorf("Expected log file to contain the message, got: %s", data)
+	}
+}
@@ -26,14 +26,16 @@ type TokenResponse struct {
 
 // IntrospectionResponse represents the response from token introspection endpoint
 type IntrospectionResponse struct {
-	Active   bool                   `json:"active"`
-	Sub      string                 `json:"sub"`
-	Aud      interface{}            `json:"aud"`
-	Iss      string                 `json:"iss"`
-	Exp      int64                  `json:"exp"`
-	Iat      int64                  `json:"iat"`
-	TokenUse string                 `json:"token_use"`
-	Claims   map[string]interface{} `json:"claims"`
+	Active    bool                   `json:"active"`
+	TokenType string                 `json:"token_type"`
+	Sub       string                 `json:"sub"`
+	Aud       interface{}            `json:"aud"`
+	Iss       string                 `json:"iss"`
+	Exp       int64                  `json:"exp"`
+	Iat       int64                  `json:"iat"`
+	TokenUse  string                 `json:"token_use"`
+	Cnf       map[string]interface{} `json:"cnf"`
+	Claims    map[string]interface{} `json:"claims"`
 }
 
 // HealthResponse represents the response from health endpoint
@@ -83,4 +85,20 @@ type KeyInfo struct {
 	ID        string `json:"id"`
 	Algorithm string `json:"algorithm"`
 	Use       string `json:"use"`
-}
\ No newline at end of file
+}
+
+// OAuthTokenResponse represents a response from the OAuth 2.0 token endpoint
+type OAuthTokenResponse struct {
+	AccessToken     string `json:"access_token"`
+	IssuedTokenType string `json:"issued_token_type"`
+	TokenType       string `json:"token_type"`
+	ExpiresIn       int    `json:"expires_in"`
+	Scope           string `json:"scope"`
+	IDToken         string `json:"id_token"`
+}
+
+// OAuthErrorResponse represents an OAuth 2.0 error response
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
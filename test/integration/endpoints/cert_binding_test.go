@@ -0,0 +1,87 @@
+package endpoints
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shogotsuneto/jwks-mock-api/test/integration/common"
+)
+
+// clientCertificatePEM creates a self-signed client certificate and returns it as PEM
+// along with its base64url SHA-256 thumbprint
+func clientCertificatePEM(t *testing.T) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate certificate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "integration-mtls-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	sum := sha256.Sum256(der)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// TestCertificateBoundTokens tests binding generated tokens to a client certificate (RFC 8705)
+func TestCertificateBoundTokens(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	certPEM, thumbprint := clientCertificatePEM(t)
+
+	requests := map[string]map[string]interface{}{
+		"PEM certificate": {"claims": map[string]interface{}{"sub": "mtls-user"}, "clientCertificate": certPEM},
+		"thumbprint":      {"claims": map[string]interface{}{"sub": "mtls-user"}, "certificateThumbprint": thumbprint},
+	}
+
+	for name, request := range requests {
+		t.Run(name, func(t *testing.T) {
+			resp, body := its.MakeRequest(t, "POST", "/generate-token", request, nil)
+			common.AssertStatusCode(t, resp, http.StatusOK)
+
+			var tokenResp common.TokenResponse
+			common.AssertJSONResponse(t, body, &tokenResp)
+
+			token := common.AssertValidJWT(t, tokenResp.Token)
+			cnf, _ := token.Claims.(jwt.MapClaims)["cnf"].(map[string]interface{})
+			if cnf["x5t#S256"] != thumbprint {
+				t.Errorf("❌ CERTIFICATE BINDING FAILED: Expected cnf.x5t#S256 %s, got %v", thumbprint, cnf["x5t#S256"])
+			}
+
+			introspection := introspectToken(t, its, tokenResp.Token)
+			if introspection.Cnf["x5t#S256"] != thumbprint {
+				t.Errorf("❌ CERTIFICATE BINDING FAILED: Expected introspected cnf.x5t#S256 %s, got %v", thumbprint, introspection.Cnf)
+			}
+		})
+	}
+
+	resp, body := its.MakeRequest(t, "POST", "/generate-token", map[string]interface{}{
+		"claims":            map[string]interface{}{"sub": "mtls-user"},
+		"clientCertificate": "not a certificate",
+	}, nil)
+	common.AssertStatusCode(t, resp, http.StatusBadRequest)
+	common.AssertResponseContains(t, body, "clientCertificate")
+
+	t.Log("✅ Certificate-bound tokens passed")
+}
@@ -0,0 +1,104 @@
+package endpoints
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shogotsuneto/jwks-mock-api/test/integration/common"
+)
+
+const (
+	jwtClientID         = "integration-jwt-client"
+	clientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+)
+
+// signClientAssertion builds a private_key_jwt client assertion (RFC 7523) for the JWT client
+func signClientAssertion(t *testing.T, audience string) string {
+	t.Helper()
+
+	claims := jwt.MapClaims{
+		"iss": jwtClientID,
+		"sub": jwtClientID,
+		"aud": audience,
+		"jti": time.Now().Format(time.RFC3339Nano),
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Minute).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = clientKeyID
+	signed, err := token.SignedString(clientPrivateKey(t))
+	if err != nil {
+		t.Fatalf("❌ CLIENT ASSERTION FAILED: Could not sign assertion: %v", err)
+	}
+	return signed
+}
+
+// TestPrivateKeyJWTClientAuthentication tests authenticating at the token endpoint with a client assertion
+func TestPrivateKeyJWTClientAuthentication(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	assertion := signClientAssertion(t, integrationIssuer+"/token")
+	form := url.Values{
+		"grant_type":            {"password"},
+		"username":              {"alice"},
+		"password":              {"alice-password"},
+		"client_assertion_type": {clientAssertionType},
+		"client_assertion":      {assertion},
+	}
+
+	resp, body := postTokenRequest(t, its, form)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	var tokenResp common.OAuthTokenResponse
+	common.AssertJSONResponse(t, body, &tokenResp)
+	common.AssertJWTClaims(t, common.AssertValidJWT(t, tokenResp.AccessToken), map[string]interface{}{
+		"sub":       "password-user",
+		"client_id": jwtClientID,
+	})
+
+	// Assertions are single use
+	resp, body = postTokenRequest(t, its, form)
+	common.AssertStatusCode(t, resp, http.StatusUnauthorized)
+	common.AssertResponseContains(t, body, "invalid_client", "already been used")
+
+	t.Log("✅ private_key_jwt client authentication passed")
+}
+
+// TestPrivateKeyJWTClientAuthenticationFailures tests rejected client assertions
+func TestPrivateKeyJWTClientAuthenticationFailures(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	passwordForm := func(extra url.Values) url.Values {
+		form := url.Values{
+			"grant_type": {"password"},
+			"username":   {"alice"},
+			"password":   {"alice-password"},
+		}
+		for name, values := range extra {
+			form[name] = values
+		}
+		return form
+	}
+
+	// Wrong audience
+	resp, body := postTokenRequest(t, its, passwordForm(url.Values{
+		"client_assertion_type": {clientAssertionType},
+		"client_assertion":      {signClientAssertion(t, "https://other-server.test/token")},
+	}))
+	common.AssertStatusCode(t, resp, http.StatusUnauthorized)
+	common.AssertResponseContains(t, body, "invalid_client", "aud")
+
+	// The client is registered for private_key_jwt and cannot omit its assertion
+	resp, body = postTokenRequest(t, its, passwordForm(url.Values{
+		"client_id": {jwtClientID},
+	}))
+	common.AssertStatusCode(t, resp, http.StatusUnauthorized)
+	common.AssertResponseContains(t, body, "invalid_client")
+
+	t.Log("✅ private_key_jwt rejection cases passed")
+}
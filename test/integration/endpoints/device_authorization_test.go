@@ -0,0 +1,133 @@
+package endpoints
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/shogotsuneto/jwks-mock-api/test/integration/common"
+)
+
+const deviceCodeGrant = "urn:ietf:params:oauth:grant-type:device_code"
+
+// deviceAuthorizationResponse represents the device authorization endpoint response
+type deviceAuthorizationResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// startDeviceAuthorization starts a device authorization request for the CLI client
+func startDeviceAuthorization(t *testing.T, its *common.IntegrationTestSuite) deviceAuthorizationResponse {
+	t.Helper()
+
+	resp, body := its.MakeRequest(t, "POST", "/device_authorization", url.Values{
+		"client_id": {"integration-cli"},
+		"scope":     {"openid profile"},
+	}, map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	common.AssertContentType(t, resp, "application/json")
+
+	var deviceResp deviceAuthorizationResponse
+	common.AssertJSONResponse(t, body, &deviceResp)
+
+	if deviceResp.DeviceCode == "" || deviceResp.UserCode == "" {
+		t.Fatalf("❌ DEVICE AUTHORIZATION FAILED: Expected device and user codes, got %s", string(body))
+	}
+	return deviceResp
+}
+
+// pollDeviceToken polls the token endpoint with a device code and returns the error code, if any
+func pollDeviceToken(t *testing.T, its *common.IntegrationTestSuite, deviceCode string) (string, common.OAuthTokenResponse) {
+	t.Helper()
+
+	resp, body := postTokenRequest(t, its, url.Values{
+		"grant_type":  {deviceCodeGrant},
+		"device_code": {deviceCode},
+		"client_id":   {"integration-cli"},
+	})
+
+	var tokenResp common.OAuthTokenResponse
+	if resp.StatusCode == http.StatusOK {
+		common.AssertJSONResponse(t, body, &tokenResp)
+		return "", tokenResp
+	}
+
+	var errResp common.OAuthErrorResponse
+	common.AssertJSONResponse(t, body, &errResp)
+	return errResp.Error, tokenResp
+}
+
+// TestDeviceAuthorizationApproved tests the device flow through to an issued token
+func TestDeviceAuthorizationApproved(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	deviceResp := startDeviceAuthorization(t, its)
+
+	if errCode, _ := pollDeviceToken(t, its, deviceResp.DeviceCode); errCode != "authorization_pending" {
+		t.Errorf("❌ DEVICE FLOW FAILED: Expected authorization_pending, got %s", errCode)
+	}
+
+	// The verification page shows the pending request
+	resp, body := its.MakeRequest(t, "GET", "/device?user_code="+url.QueryEscape(deviceResp.UserCode), nil, nil)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	common.AssertContentType(t, resp, "text/html")
+	common.AssertResponseContains(t, body, "integration-cli")
+
+	resp, _ = its.MakeRequest(t, "POST", "/device", url.Values{
+		"user_code": {deviceResp.UserCode},
+		"sub":       {"device-user"},
+		"action":    {"approve"},
+	}, map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	errCode, tokenResp := pollDeviceToken(t, its, deviceResp.DeviceCode)
+	if errCode != "" {
+		t.Fatalf("❌ DEVICE FLOW FAILED: Expected a token after approval, got %s", errCode)
+	}
+
+	token := common.AssertValidJWT(t, tokenResp.AccessToken)
+	common.AssertJWTClaims(t, token, map[string]interface{}{
+		"sub":       "device-user",
+		"client_id": "integration-cli",
+		"scope":     "openid profile",
+	})
+
+	// Device codes are single use
+	if errCode, _ := pollDeviceToken(t, its, deviceResp.DeviceCode); errCode != "invalid_grant" {
+		t.Errorf("❌ DEVICE FLOW FAILED: Expected invalid_grant on reuse, got %s", errCode)
+	}
+
+	t.Log("✅ Device authorization approval test passed")
+}
+
+// TestDeviceAuthorizationDenied tests the access_denied response
+func TestDeviceAuthorizationDenied(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	deviceResp := startDeviceAuthorization(t, its)
+
+	resp, _ := its.MakeRequest(t, "POST", "/device", url.Values{
+		"user_code": {deviceResp.UserCode},
+		"action":    {"deny"},
+	}, map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	if errCode, _ := pollDeviceToken(t, its, deviceResp.DeviceCode); errCode != "access_denied" {
+		t.Errorf("❌ DEVICE FLOW FAILED: Expected access_denied, got %s", errCode)
+	}
+
+	// Unknown user codes are rejected by the verification page
+	resp, _ = its.MakeRequest(t, "POST", "/device", url.Values{
+		"user_code": {"BCDF-GHJK"},
+		"action":    {"approve"},
+	}, map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
+	common.AssertStatusCode(t, resp, http.StatusBadRequest)
+
+	t.Log("✅ Device authorization denial test passed")
+}
@@ -0,0 +1,150 @@
+package endpoints
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shogotsuneto/jwks-mock-api/test/integration/common"
+)
+
+// dpopKey is a DPoP key pair with its public JWK and thumbprint
+type dpopKey struct {
+	private    *ecdsa.PrivateKey
+	jwk        map[string]interface{}
+	thumbprint string
+}
+
+// newDPoPKey generates a P-256 DPoP key
+func newDPoPKey(t *testing.T) *dpopKey {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate DPoP key: %v", err)
+	}
+
+	x := base64.RawURLEncoding.EncodeToString(key.PublicKey.X.FillBytes(make([]byte, 32)))
+	y := base64.RawURLEncoding.EncodeToString(key.PublicKey.Y.FillBytes(make([]byte, 32)))
+
+	// JWK thumbprint over the required members in lexicographic order (RFC 7638)
+	sum := sha256.Sum256([]byte(`{"crv":"P-256","kty":"EC","x":"` + x + `","y":"` + y + `"}`))
+
+	return &dpopKey{
+		private:    key,
+		jwk:        map[string]interface{}{"kty": "EC", "crv": "P-256", "x": x, "y": y},
+		thumbprint: base64.RawURLEncoding.EncodeToString(sum[:]),
+	}
+}
+
+// proof signs a DPoP proof for a POST to the given issuer path
+func (k *dpopKey) proof(t *testing.T, path string) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"htm": "POST",
+		"htu": integrationIssuer + path,
+		"iat": time.Now().Unix(),
+		"jti": time.Now().Format(time.RFC3339Nano),
+	})
+	token.Header["typ"] = "dpop+jwt"
+	token.Header["jwk"] = k.jwk
+	signed, err := token.SignedString(k.private)
+	if err != nil {
+		t.Fatalf("Failed to sign DPoP proof: %v", err)
+	}
+	return signed
+}
+
+// TestDPoPTokenRequest tests issuing a DPoP-bound access token at the token endpoint (RFC 9449)
+func TestDPoPTokenRequest(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	key := newDPoPKey(t)
+	proof := key.proof(t, "/token")
+	form := url.Values{
+		"grant_type": {"password"},
+		"username":   {"alice"},
+		"password":   {"alice-password"},
+	}
+
+	resp, body := its.MakeRequest(t, "POST", "/token", form, map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+		"DPoP":         proof,
+	})
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	var tokenResp common.OAuthTokenResponse
+	common.AssertJSONResponse(t, body, &tokenResp)
+	if tokenResp.TokenType != "DPoP" {
+		t.Errorf("❌ DPOP FAILED: Expected token_type DPoP, got %s", tokenResp.TokenType)
+	}
+
+	token := common.AssertValidJWT(t, tokenResp.AccessToken)
+	cnf, _ := token.Claims.(jwt.MapClaims)["cnf"].(map[string]interface{})
+	if cnf["jkt"] != key.thumbprint {
+		t.Errorf("❌ DPOP FAILED: Expected cnf.jkt %s, got %v", key.thumbprint, cnf["jkt"])
+	}
+
+	introspection := introspectToken(t, its, tokenResp.AccessToken)
+	if introspection.TokenType != "DPoP" {
+		t.Errorf("❌ DPOP FAILED: Expected introspected token_type DPoP, got %s", introspection.TokenType)
+	}
+
+	// Proofs cannot be replayed
+	resp, body = its.MakeRequest(t, "POST", "/token", form, map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+		"DPoP":         proof,
+	})
+	common.AssertStatusCode(t, resp, http.StatusBadRequest)
+	common.AssertResponseContains(t, body, "invalid_dpop_proof")
+
+	// Proofs are bound to the target URL
+	resp, body = its.MakeRequest(t, "POST", "/token", form, map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+		"DPoP":         key.proof(t, "/introspect"),
+	})
+	common.AssertStatusCode(t, resp, http.StatusBadRequest)
+	common.AssertResponseContains(t, body, "invalid_dpop_proof", "htu")
+
+	t.Log("✅ DPoP token request passed")
+}
+
+// TestGenerateTokenWithDPoPThumbprint tests binding a generated token to a supplied JWK thumbprint
+func TestGenerateTokenWithDPoPThumbprint(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	key := newDPoPKey(t)
+	resp, body := its.MakeRequest(t, "POST", "/generate-token", map[string]interface{}{
+		"claims":  map[string]interface{}{"sub": "dpop-user"},
+		"dpopJkt": key.thumbprint,
+	}, nil)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	var tokenResp common.TokenResponse
+	common.AssertJSONResponse(t, body, &tokenResp)
+
+	token := common.AssertValidJWT(t, tokenResp.Token)
+	cnf, _ := token.Claims.(jwt.MapClaims)["cnf"].(map[string]interface{})
+	if cnf["jkt"] != key.thumbprint {
+		t.Errorf("❌ DPOP FAILED: Expected cnf.jkt %s, got %v", key.thumbprint, cnf["jkt"])
+	}
+
+	resp, body = its.MakeRequest(t, "POST", "/generate-token", map[string]interface{}{
+		"claims":  map[string]interface{}{"sub": "dpop-user"},
+		"dpopJkt": "not-a-thumbprint",
+	}, nil)
+	common.AssertStatusCode(t, resp, http.StatusBadRequest)
+	common.AssertResponseContains(t, body, "dpopJkt")
+
+	t.Log("✅ Generate token with DPoP thumbprint passed")
+}
@@ -0,0 +1,85 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/shogotsuneto/jwks-mock-api/test/integration/common"
+)
+
+// TestFaultInjection tests injecting JWKS endpoint failures through the admin API. The faults
+// target a tenant's JWKS endpoint so other tests fetching the top-level JWKS are unaffected.
+func TestFaultInjection(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	resp, _ := its.MakeRequest(t, "POST", "/tenants", map[string]interface{}{"name": "faults-tenant"}, nil)
+	common.AssertStatusCode(t, resp, http.StatusCreated)
+	t.Cleanup(func() {
+		its.MakeRequest(t, "DELETE", "/faults", nil, nil)
+		its.MakeRequest(t, "DELETE", "/tenants/faults-tenant", nil, nil)
+	})
+	jwksPath := "/tenants/faults-tenant/.well-known/jwks.json"
+
+	// The next two requests fail with 503, then the endpoint recovers
+	resp, body := its.MakeRequest(t, "POST", "/faults", map[string]interface{}{
+		"path":   jwksPath,
+		"type":   "status",
+		"status": 503,
+		"count":  2,
+	}, nil)
+	common.AssertStatusCode(t, resp, http.StatusCreated)
+	common.AssertResponseContains(t, body, `"success":true`, "fault-")
+
+	for i := 0; i < 2; i++ {
+		resp, _ = its.MakeRequest(t, "GET", jwksPath, nil, nil)
+		common.AssertStatusCode(t, resp, http.StatusServiceUnavailable)
+		if resp.Header.Get("Retry-After") == "" {
+			t.Error("❌ FAULT FAILED: Expected Retry-After on 503")
+		}
+	}
+	resp, _ = its.MakeRequest(t, "GET", jwksPath, nil, nil)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	// Malformed JSON
+	resp, _ = its.MakeRequest(t, "POST", "/faults", map[string]interface{}{"path": jwksPath, "type": "malformed", "count": 1}, nil)
+	common.AssertStatusCode(t, resp, http.StatusCreated)
+	resp, body = its.MakeRequest(t, "GET", jwksPath, nil, nil)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	var jwks common.JWKSResponse
+	if err := json.Unmarshal(body, &jwks); err == nil {
+		t.Error("❌ FAULT FAILED: Expected a malformed JWKS body")
+	}
+
+	// Empty key set
+	resp, _ = its.MakeRequest(t, "POST", "/faults", map[string]interface{}{"path": jwksPath, "type": "empty_keys", "count": 1}, nil)
+	common.AssertStatusCode(t, resp, http.StatusCreated)
+	resp, body = its.MakeRequest(t, "GET", jwksPath, nil, nil)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	common.AssertJSONResponse(t, body, &jwks)
+	if len(jwks.Keys) != 0 {
+		t.Errorf("❌ FAULT FAILED: Expected an empty key set, got %d keys", len(jwks.Keys))
+	}
+
+	// Unlimited faults stay listed until removed
+	resp, _ = its.MakeRequest(t, "POST", "/faults", map[string]interface{}{"path": jwksPath, "type": "content_type"}, nil)
+	common.AssertStatusCode(t, resp, http.StatusCreated)
+	resp, _ = its.MakeRequest(t, "GET", jwksPath, nil, nil)
+	common.AssertContentType(t, resp, "text/html")
+
+	resp, body = its.MakeRequest(t, "GET", "/faults", nil, nil)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	common.AssertResponseContains(t, body, `"total_faults":1`, `"type":"content_type"`)
+
+	resp, body = its.MakeRequest(t, "DELETE", "/faults", nil, nil)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	common.AssertResponseContains(t, body, `"cleared":1`)
+	resp, _ = its.MakeRequest(t, "GET", jwksPath, nil, nil)
+	common.AssertContentType(t, resp, "application/json")
+
+	resp, _ = its.MakeRequest(t, "POST", "/faults", map[string]interface{}{"path": jwksPath, "type": "explode"}, nil)
+	common.AssertStatusCode(t, resp, http.StatusBadRequest)
+
+	t.Log("✅ Fault injection passed")
+}
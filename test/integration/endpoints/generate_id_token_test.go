@@ -0,0 +1,100 @@
+package endpoints
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shogotsuneto/jwks-mock-api/test/integration/common"
+)
+
+// TestIDTokenGeneration tests OpenID Connect ID token generation
+func TestIDTokenGeneration(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	tokenReq := map[string]interface{}{
+		"claims": map[string]interface{}{
+			"sub":  "id-token-user",
+			"name": "ID Token User",
+		},
+		"idToken": map[string]interface{}{
+			"clientId":    "integration-client",
+			"nonce":       "n-0S6_WzA2Mj",
+			"accessToken": "jHkWEdUXMU1BwAsC4vtUsZwnNvTIxEl0z9K3vx5KF0Y",
+			"code":        "Qcb0Orv1zh30vL1MPRsbm-diHiMwcLyZvn1arpZv-Jxf_11jnpEX3Tgfvk",
+			"acr":         "urn:mace:incommon:iap:silver",
+		},
+	}
+
+	resp, body := its.MakeRequest(t, "POST", "/generate-token", tokenReq, nil)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	var tokenResp common.TokenResponse
+	common.AssertJSONResponse(t, body, &tokenResp)
+
+	token := common.AssertValidJWT(t, tokenResp.Token)
+	common.AssertJWTClaims(t, token, map[string]interface{}{
+		"sub":     "id-token-user",
+		"aud":     "integration-client",
+		"nonce":   "n-0S6_WzA2Mj",
+		"acr":     "urn:mace:incommon:iap:silver",
+		"at_hash": "77QmUPtjPfzWtF2AnpK9RQ",
+		"c_hash":  "LDktKdoQak3Pk0cnXxCltA",
+	})
+
+	claims := token.Claims.(jwt.MapClaims)
+	if _, ok := claims["auth_time"].(float64); !ok {
+		t.Errorf("❌ ID TOKEN FAILED: Expected auth_time claim, got %v", claims["auth_time"])
+	}
+	if _, ok := claims["azp"]; ok {
+		t.Errorf("❌ ID TOKEN FAILED: Expected no azp for a single audience, got %v", claims["azp"])
+	}
+
+	t.Log("✅ ID token generation test passed")
+}
+
+// TestIDTokenMultipleAudiences tests that azp is set when the ID token has several audiences
+func TestIDTokenMultipleAudiences(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	tokenReq := map[string]interface{}{
+		"claims": map[string]interface{}{"sub": "id-token-user"},
+		"idToken": map[string]interface{}{
+			"clientId":            "integration-client",
+			"additionalAudiences": []string{"other-service"},
+		},
+	}
+
+	resp, body := its.MakeRequest(t, "POST", "/generate-token", tokenReq, nil)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	var tokenResp common.TokenResponse
+	common.AssertJSONResponse(t, body, &tokenResp)
+
+	token := common.AssertValidJWT(t, tokenResp.Token)
+	common.AssertJWTClaims(t, token, map[string]interface{}{
+		"aud": []interface{}{"integration-client", "other-service"},
+		"azp": "integration-client",
+	})
+
+	t.Log("✅ ID token audience test passed")
+}
+
+// TestIDTokenRequiresClientID tests that ID token generation without a client ID is rejected
+func TestIDTokenRequiresClientID(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	tokenReq := map[string]interface{}{
+		"claims":  map[string]interface{}{"sub": "id-token-user"},
+		"idToken": map[string]interface{}{"nonce": "abc"},
+	}
+
+	resp, body := its.MakeRequest(t, "POST", "/generate-token", tokenReq, nil)
+	common.AssertStatusCode(t, resp, http.StatusBadRequest)
+	common.AssertResponseContains(t, body, "clientId")
+
+	t.Log("✅ ID token client ID validation test passed")
+}
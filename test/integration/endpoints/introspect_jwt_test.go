@@ -0,0 +1,96 @@
+package endpoints
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shogotsuneto/jwks-mock-api/test/integration/common"
+)
+
+// TestIntrospectionJWTResponse tests JWT-secured introspection responses (RFC 9701)
+func TestIntrospectionJWTResponse(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	token := generateUserInfoToken(t, its, "jwt-introspection-user", "read write")
+
+	formData := url.Values{"token": {token}}
+	resp, body := its.MakeRequest(t, "POST", "/introspect", formData, map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+		"Accept":       "application/token-introspection+jwt",
+	})
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	common.AssertContentType(t, resp, "application/token-introspection+jwt")
+
+	responseToken := common.AssertValidJWT(t, string(body))
+	if responseToken.Header["typ"] != "token-introspection+jwt" {
+		t.Errorf("❌ JWT INTROSPECTION FAILED: Expected typ 'token-introspection+jwt', got %v", responseToken.Header["typ"])
+	}
+	if _, ok := responseToken.Header["kid"].(string); !ok {
+		t.Error("❌ JWT INTROSPECTION FAILED: Expected kid in response header")
+	}
+
+	common.AssertJWTClaims(t, responseToken, map[string]interface{}{
+		"iss": "http://jwks-api:3000",
+		"aud": "integration-test-api",
+	})
+
+	claims := responseToken.Claims.(jwt.MapClaims)
+	if _, ok := claims["iat"].(float64); !ok {
+		t.Error("❌ JWT INTROSPECTION FAILED: Expected iat claim")
+	}
+
+	introspection, ok := claims["token_introspection"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("❌ JWT INTROSPECTION FAILED: Expected token_introspection object, got %v", claims["token_introspection"])
+	}
+	if introspection["active"] != true {
+		t.Errorf("❌ JWT INTROSPECTION FAILED: Expected active=true, got %v", introspection["active"])
+	}
+	if introspection["sub"] != "jwt-introspection-user" {
+		t.Errorf("❌ JWT INTROSPECTION FAILED: Expected sub 'jwt-introspection-user', got %v", introspection["sub"])
+	}
+
+	// Inactive tokens are wrapped as well
+	formData = url.Values{"token": {"not-a-jwt"}}
+	resp, body = its.MakeRequest(t, "POST", "/introspect", formData, map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+		"Accept":       "application/token-introspection+jwt",
+	})
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	inactiveToken := common.AssertValidJWT(t, string(body))
+	inactive := inactiveToken.Claims.(jwt.MapClaims)["token_introspection"].(map[string]interface{})
+	if inactive["active"] != false {
+		t.Errorf("❌ JWT INTROSPECTION FAILED: Expected active=false, got %v", inactive["active"])
+	}
+
+	t.Log("✅ JWT-secured introspection test passed")
+}
+
+// TestIntrospectionJWTAudience tests that the JWT audience is the authenticated resource server
+func TestIntrospectionJWTAudience(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	token := generateUserInfoToken(t, its, "jwt-introspection-user", "read")
+
+	credentials := base64.StdEncoding.EncodeToString([]byte("integration-resource-server:integration-resource-secret"))
+	formData := url.Values{"token": {token}}
+	resp, body := its.MakeRequest(t, "POST", "/introspect", formData, map[string]string{
+		"Content-Type":  "application/x-www-form-urlencoded",
+		"Accept":        "application/token-introspection+jwt",
+		"Authorization": "Basic " + credentials,
+	})
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	responseToken := common.AssertValidJWT(t, string(body))
+	common.AssertJWTClaims(t, responseToken, map[string]interface{}{
+		"aud": "integration-resource-server",
+	})
+
+	t.Log("✅ JWT-secured introspection audience test passed")
+}
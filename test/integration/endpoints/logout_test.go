@@ -0,0 +1,111 @@
+package endpoints
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shogotsuneto/jwks-mock-api/test/integration/common"
+)
+
+// logoutDelivery mirrors an entry of the logout delivery log
+type logoutDelivery struct {
+	ClientID    string `json:"client_id"`
+	Channel     string `json:"channel"`
+	URI         string `json:"uri"`
+	Subject     string `json:"sub"`
+	SessionID   string `json:"sid"`
+	LogoutToken string `json:"logout_token"`
+	Error       string `json:"error"`
+}
+
+// TestEndSessionLogout tests RP-initiated logout with back-channel and front-channel notifications
+func TestEndSessionLogout(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	// Nothing listens on the back-channel URI, so its delivery is logged with an error
+	info := registerClient(t, its, map[string]interface{}{
+		"client_name":                          "Logout Client",
+		"grant_types":                          []string{"password"},
+		"token_endpoint_auth_method":           "none",
+		"backchannel_logout_uri":               "http://127.0.0.1:1/backchannel-logout",
+		"frontchannel_logout_uri":              "https://logout.integration.test/frontchannel",
+		"frontchannel_logout_session_required": true,
+		"post_logout_redirect_uris":            []string{"https://logout.integration.test/bye"},
+	})
+
+	resp, _ := its.MakeRequest(t, "DELETE", "/logout-deliveries", nil, nil)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	resp, body := its.MakeRequest(t, "POST", "/token", url.Values{
+		"grant_type": {"password"},
+		"username":   {"alice"},
+		"password":   {"alice-password"},
+		"client_id":  {info.ClientID},
+		"scope":      {"openid"},
+	}, map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	var tokenResp common.OAuthTokenResponse
+	common.AssertJSONResponse(t, body, &tokenResp)
+	idToken := common.AssertValidJWT(t, tokenResp.IDToken)
+	sid, _ := idToken.Claims.(jwt.MapClaims)["sid"].(string)
+	if sid == "" {
+		t.Fatal("❌ LOGOUT FAILED: Expected a sid claim in the ID token")
+	}
+
+	query := url.Values{
+		"id_token_hint":            {tokenResp.IDToken},
+		"post_logout_redirect_uri": {"https://logout.integration.test/bye"},
+		"state":                    {"logout-state"},
+	}
+	resp, body = its.MakeRequest(t, "GET", "/end_session?"+query.Encode(), nil, nil)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	common.AssertResponseContains(t, body, "https://logout.integration.test/frontchannel?iss=", "sid="+sid, "logout-state")
+
+	resp, body = its.MakeRequest(t, "GET", "/logout-deliveries", nil, nil)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	var deliveries struct {
+		TotalDeliveries int              `json:"total_deliveries"`
+		Deliveries      []logoutDelivery `json:"deliveries"`
+	}
+	common.AssertJSONResponse(t, body, &deliveries)
+	if deliveries.TotalDeliveries != 2 {
+		t.Fatalf("❌ LOGOUT FAILED: Expected 2 deliveries, got %s", string(body))
+	}
+
+	for _, delivery := range deliveries.Deliveries {
+		if delivery.ClientID != info.ClientID || delivery.SessionID != sid || delivery.Subject != "password-user" {
+			t.Errorf("❌ LOGOUT FAILED: Unexpected delivery %+v", delivery)
+		}
+		if delivery.Channel != "back_channel" {
+			continue
+		}
+		if delivery.Error == "" {
+			t.Error("❌ LOGOUT FAILED: Expected the unreachable back-channel delivery to record an error")
+		}
+
+		logoutToken := common.AssertValidJWT(t, delivery.LogoutToken)
+		if logoutToken.Header["typ"] != "logout+jwt" {
+			t.Errorf("❌ LOGOUT FAILED: Expected typ logout+jwt, got %v", logoutToken.Header["typ"])
+		}
+		events, _ := logoutToken.Claims.(jwt.MapClaims)["events"].(map[string]interface{})
+		if _, ok := events["http://schemas.openid.net/event/backchannel-logout"]; !ok {
+			t.Errorf("❌ LOGOUT FAILED: Expected the back-channel logout event, got %v", events)
+		}
+		common.AssertJWTClaims(t, logoutToken, map[string]interface{}{
+			"aud": info.ClientID,
+			"sid": sid,
+		})
+	}
+
+	// Unregistered post-logout redirect URIs are refused
+	query.Set("post_logout_redirect_uri", "https://attacker.integration.test/")
+	resp, _ = its.MakeRequest(t, "GET", "/end_session?"+query.Encode(), nil, nil)
+	common.AssertStatusCode(t, resp, http.StatusBadRequest)
+
+	t.Log("✅ End session logout passed")
+}
@@ -0,0 +1,45 @@
+package endpoints
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/shogotsuneto/jwks-mock-api/test/integration/common"
+)
+
+// TestMetrics tests the Prometheus metrics endpoint
+func TestMetrics(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	resp, body := its.MakeRequest(t, "POST", "/generate-token", map[string]interface{}{
+		"claims": map[string]interface{}{"sub": "metrics-user"},
+	}, nil)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	var tokenResp common.TokenResponse
+	common.AssertJSONResponse(t, body, &tokenResp)
+
+	introspectToken(t, its, tokenResp.Token)
+	resp, _ = its.MakeRequest(t, "POST", "/introspect", url.Values{"token": {"not-a-jwt"}}, map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	})
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	resp, body = its.MakeRequest(t, "GET", "/metrics", nil, nil)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	common.AssertContentType(t, resp, "text/plain")
+	common.AssertResponseContains(t, body,
+		"# TYPE jwks_mock_http_requests_total counter",
+		`jwks_mock_http_requests_total{method="POST",route="/generate-token",status="200"}`,
+		"# TYPE jwks_mock_http_request_duration_seconds histogram",
+		`jwks_mock_http_request_duration_seconds_bucket{method="POST",route="/introspect",status="200",le="+Inf"}`,
+		`jwks_mock_tokens_issued_total{kid="`+tokenResp.KeyID+`",alg="RS256"}`,
+		`jwks_mock_introspections_total{active="true",reason=""}`,
+		`jwks_mock_introspections_total{active="false",reason="malformed"}`,
+		`jwks_mock_keys{issuer="`+integrationIssuer+`"}`,
+		"# TYPE jwks_mock_key_rotations_total counter",
+	)
+
+	t.Log("✅ Metrics passed")
+}
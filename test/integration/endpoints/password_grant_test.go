@@ -0,0 +1,85 @@
+package endpoints
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/shogotsuneto/jwks-mock-api/test/integration/common"
+)
+
+// TestPasswordGrant tests the resource owner password credentials grant
+func TestPasswordGrant(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	resp, body := postTokenRequest(t, its, url.Values{
+		"grant_type": {"password"},
+		"username":   {"alice"},
+		"password":   {"alice-password"},
+		"client_id":  {"legacy-app"},
+		"scope":      {"openid api"},
+	})
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	var tokenResp common.OAuthTokenResponse
+	common.AssertJSONResponse(t, body, &tokenResp)
+
+	token := common.AssertValidJWT(t, tokenResp.AccessToken)
+	common.AssertJWTClaims(t, token, map[string]interface{}{
+		"sub":       "password-user",
+		"client_id": "legacy-app",
+		"scope":     "openid api",
+		"roles":     []interface{}{"user"},
+	})
+
+	if tokenResp.IDToken == "" {
+		t.Fatal("❌ PASSWORD GRANT FAILED: Expected an ID token for the openid scope")
+	}
+	idToken := common.AssertValidJWT(t, tokenResp.IDToken)
+	common.AssertJWTClaims(t, idToken, map[string]interface{}{
+		"sub": "password-user",
+		"aud": "legacy-app",
+	})
+
+	t.Log("✅ Password grant test passed")
+}
+
+// TestPasswordGrantFailures tests bad credentials and account state errors
+func TestPasswordGrantFailures(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	tests := []struct {
+		name                string
+		username            string
+		password            string
+		expectedDescription string
+	}{
+		{"wrong password", "alice", "wrong", "Invalid username or password"},
+		{"unknown user", "nobody", "secret", "Invalid username or password"},
+		{"locked account", "locked", "locked-password", "Account is locked"},
+		{"disabled account", "disabled", "disabled-password", "Account is disabled"},
+		{"expired password", "expired", "expired-password", "Password has expired"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := postTokenRequest(t, its, url.Values{
+				"grant_type": {"password"},
+				"username":   {tt.username},
+				"password":   {tt.password},
+			})
+			common.AssertStatusCode(t, resp, http.StatusBadRequest)
+
+			var errResp common.OAuthErrorResponse
+			common.AssertJSONResponse(t, body, &errResp)
+			if errResp.Error != "invalid_grant" || errResp.ErrorDescription != tt.expectedDescription {
+				t.Errorf("❌ PASSWORD GRANT FAILED: Expected invalid_grant (%s), got %s (%s)",
+					tt.expectedDescription, errResp.Error, errResp.ErrorDescription)
+			}
+		})
+	}
+
+	t.Log("✅ Password grant failure test passed")
+}
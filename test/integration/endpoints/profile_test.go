@@ -0,0 +1,51 @@
+package endpoints
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shogotsuneto/jwks-mock-api/test/integration/common"
+)
+
+// TestGenerateTokenRFC9068Profile tests generating JWT access tokens in the RFC 9068 profile
+func TestGenerateTokenRFC9068Profile(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	resp, body := its.MakeRequest(t, "POST", "/generate-token", map[string]interface{}{
+		"claims":  map[string]interface{}{"sub": "profile-user", "client_id": "profile-client", "scope": "read"},
+		"profile": "rfc9068",
+	}, nil)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	var tokenResp common.TokenResponse
+	common.AssertJSONResponse(t, body, &tokenResp)
+
+	token := common.AssertValidJWT(t, tokenResp.Token)
+	if token.Header["typ"] != "at+jwt" {
+		t.Errorf("❌ PROFILE FAILED: Expected typ at+jwt, got %v", token.Header["typ"])
+	}
+	claims := token.Claims.(jwt.MapClaims)
+	for _, name := range []string{"iss", "exp", "aud", "sub", "client_id", "iat", "jti"} {
+		if _, ok := claims[name]; !ok {
+			t.Errorf("❌ PROFILE FAILED: Expected %s claim in RFC 9068 token", name)
+		}
+	}
+
+	// client_id cannot be made up for the caller
+	resp, body = its.MakeRequest(t, "POST", "/generate-token", map[string]interface{}{
+		"claims":  map[string]interface{}{"sub": "profile-user"},
+		"profile": "rfc9068",
+	}, nil)
+	common.AssertStatusCode(t, resp, http.StatusBadRequest)
+	common.AssertResponseContains(t, body, "client_id")
+
+	resp, _ = its.MakeRequest(t, "POST", "/generate-token", map[string]interface{}{
+		"claims":  map[string]interface{}{"sub": "profile-user"},
+		"profile": "unknown",
+	}, nil)
+	common.AssertStatusCode(t, resp, http.StatusBadRequest)
+
+	t.Log("✅ RFC 9068 profile token generation passed")
+}
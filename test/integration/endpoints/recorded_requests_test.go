@@ -0,0 +1,92 @@
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/shogotsuneto/jwks-mock-api/test/integration/common"
+)
+
+// recordedRequest is a request recorded by the mock
+type recordedRequest struct {
+	ID        int64               `json:"id"`
+	RequestID string              `json:"request_id"`
+	Method    string              `json:"method"`
+	Path      string              `json:"path"`
+	Headers   map[string][]string `json:"headers"`
+	Body      string              `json:"body"`
+	Token     string              `json:"token"`
+	Decision  string              `json:"decision"`
+	Reason    string              `json:"reason"`
+	Response  struct {
+		Status int    `json:"status"`
+		Body   string `json:"body"`
+	} `json:"response"`
+}
+
+// recordedRequestsResponse is the recorded request listing
+type recordedRequestsResponse struct {
+	TotalRequests int               `json:"total_requests"`
+	Capacity      int               `json:"capacity"`
+	Requests      []recordedRequest `json:"requests"`
+}
+
+// TestRecordedRequests tests inspecting the requests the mock received
+func TestRecordedRequests(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	resp, body := its.MakeRequest(t, "POST", "/generate-token", map[string]interface{}{
+		"claims": map[string]interface{}{"sub": "recorded-user"},
+	}, nil)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	var tokenResp common.TokenResponse
+	common.AssertJSONResponse(t, body, &tokenResp)
+
+	introspectToken(t, its, tokenResp.Token)
+
+	resp, body = its.MakeRequest(t, "GET", "/admin/requests?method=POST&path=/introspect&decision=active", nil, nil)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	var recorded recordedRequestsResponse
+	common.AssertJSONResponse(t, body, &recorded)
+	if recorded.Capacity != 1000 {
+		t.Errorf("❌ RECORDED REQUESTS FAILED: expected capacity 1000, got %d", recorded.Capacity)
+	}
+
+	// Other tests introspect in parallel, so select this test's token
+	var matches []recordedRequest
+	for _, request := range recorded.Requests {
+		if request.Token == tokenResp.Token {
+			matches = append(matches, request)
+		}
+	}
+	if len(matches) != 1 {
+		t.Fatalf("❌ RECORDED REQUESTS FAILED: expected the token to be introspected exactly once, got %d", len(matches))
+	}
+	request := matches[0]
+	if request.Decision != "active" || request.Response.Status != http.StatusOK || request.RequestID == "" {
+		t.Errorf("❌ RECORDED REQUESTS FAILED: unexpected recording %+v", request)
+	}
+	if len(request.Headers["Content-Type"]) == 0 || request.Headers["Content-Type"][0] != "application/x-www-form-urlencoded" {
+		t.Errorf("❌ RECORDED REQUESTS FAILED: expected the form Content-Type header, got %v", request.Headers["Content-Type"])
+	}
+	common.AssertResponseContains(t, []byte(request.Body), "token=")
+	common.AssertResponseContains(t, []byte(request.Response.Body), `"active":true`, `"sub":"recorded-user"`)
+
+	// Later requests only
+	resp, body = its.MakeRequest(t, "GET", fmt.Sprintf("/admin/requests?limit=1&since=%d", request.ID), nil, nil)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	recorded = recordedRequestsResponse{}
+	common.AssertJSONResponse(t, body, &recorded)
+	for _, later := range recorded.Requests {
+		if later.ID <= request.ID {
+			t.Errorf("❌ RECORDED REQUESTS FAILED: since=%d returned request %d", request.ID, later.ID)
+		}
+	}
+
+	resp, _ = its.MakeRequest(t, "GET", "/admin/requests?status=abc", nil, nil)
+	common.AssertStatusCode(t, resp, http.StatusBadRequest)
+
+	t.Log("✅ Recorded requests passed")
+}
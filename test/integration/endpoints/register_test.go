@@ -0,0 +1,219 @@
+package endpoints
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/shogotsuneto/jwks-mock-api/test/integration/common"
+)
+
+// clientInformation is the client information response (RFC 7591 section 3.2.1)
+type clientInformation struct {
+	ClientID                string   `json:"client_id"`
+	ClientSecret            string   `json:"client_secret"`
+	ClientName              string   `json:"client_name"`
+	RedirectURIs            []string `json:"redirect_uris"`
+	GrantTypes              []string `json:"grant_types"`
+	TokenEndpointAuthMethod string   `json:"token_endpoint_auth_method"`
+	RegistrationAccessToken string   `json:"registration_access_token"`
+	RegistrationClientURI   string   `json:"registration_client_uri"`
+}
+
+// registerClient registers a client and returns its client information
+func registerClient(t *testing.T, its *common.IntegrationTestSuite, metadata map[string]interface{}) clientInformation {
+	t.Helper()
+
+	resp, body := its.MakeRequest(t, "POST", "/register", metadata, nil)
+	common.AssertStatusCode(t, resp, http.StatusCreated)
+
+	var info clientInformation
+	common.AssertJSONResponse(t, body, &info)
+	if info.ClientID == "" || info.RegistrationAccessToken == "" {
+		t.Fatalf("❌ REGISTER FAILED: Expected client_id and registration_access_token, got %s", string(body))
+	}
+	return info
+}
+
+// TestDynamicClientRegistration tests registering a client and using it in the authorization code flow (RFC 7591)
+func TestDynamicClientRegistration(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	redirectURI := "https://registered.integration.test/callback"
+	info := registerClient(t, its, map[string]interface{}{
+		"client_name":   "Throwaway Client",
+		"redirect_uris": []string{redirectURI},
+	})
+
+	if info.ClientSecret == "" {
+		t.Error("❌ REGISTER FAILED: Expected a client_secret for a confidential client")
+	}
+	if info.TokenEndpointAuthMethod != "client_secret_basic" {
+		t.Errorf("❌ REGISTER FAILED: Expected default auth method client_secret_basic, got %s", info.TokenEndpointAuthMethod)
+	}
+	if len(info.GrantTypes) != 1 || info.GrantTypes[0] != "authorization_code" {
+		t.Errorf("❌ REGISTER FAILED: Expected default grant_types [authorization_code], got %v", info.GrantTypes)
+	}
+	if info.RegistrationClientURI != integrationIssuer+"/register/"+info.ClientID {
+		t.Errorf("❌ REGISTER FAILED: Unexpected registration_client_uri %s", info.RegistrationClientURI)
+	}
+
+	// The registered client can use the authorization code flow immediately
+	location := authorize(t, its, url.Values{
+		"response_type": {"code"},
+		"client_id":     {info.ClientID},
+		"login_hint":    {"password-user"},
+	})
+	resp, body := its.MakeRequest(t, "POST", "/token", url.Values{
+		"grant_type": {"authorization_code"},
+		"code":       {location.Query().Get("code")},
+	}, basicAuthHeader(info.ClientID, info.ClientSecret))
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	var tokenResp common.OAuthTokenResponse
+	common.AssertJSONResponse(t, body, &tokenResp)
+	common.AssertJWTClaims(t, common.AssertValidJWT(t, tokenResp.AccessToken), map[string]interface{}{
+		"client_id": info.ClientID,
+	})
+
+	// Only the registered grant types are allowed
+	resp, body = its.MakeRequest(t, "POST", "/token", url.Values{
+		"grant_type": {"password"},
+		"username":   {"alice"},
+		"password":   {"alice-password"},
+	}, basicAuthHeader(info.ClientID, info.ClientSecret))
+	common.AssertStatusCode(t, resp, http.StatusBadRequest)
+	common.AssertResponseContains(t, body, "unauthorized_client")
+
+	// Registered clients must authenticate
+	resp, body = its.MakeRequest(t, "POST", "/token", url.Values{
+		"grant_type": {"password"},
+		"username":   {"alice"},
+		"password":   {"alice-password"},
+	}, basicAuthHeader(info.ClientID, "wrong-secret"))
+	common.AssertStatusCode(t, resp, http.StatusUnauthorized)
+	common.AssertResponseContains(t, body, "invalid_client")
+
+	t.Log("✅ Dynamic client registration passed")
+}
+
+// TestClientRegistrationManagement tests reading, updating and deleting a registered client (RFC 7592)
+func TestClientRegistrationManagement(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	info := registerClient(t, its, map[string]interface{}{
+		"client_name":   "Managed Client",
+		"redirect_uris": []string{"https://managed.integration.test/callback"},
+	})
+	path := "/register/" + info.ClientID
+	auth := map[string]string{"Authorization": "Bearer " + info.RegistrationAccessToken}
+
+	// Read
+	resp, body := its.MakeRequest(t, "GET", path, nil, auth)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	var read clientInformation
+	common.AssertJSONResponse(t, body, &read)
+	if read.ClientName != "Managed Client" || read.ClientSecret != info.ClientSecret {
+		t.Errorf("❌ REGISTRATION READ FAILED: Unexpected client information %s", string(body))
+	}
+
+	// A wrong registration access token is rejected
+	resp, _ = its.MakeRequest(t, "GET", path, nil, map[string]string{"Authorization": "Bearer wrong-token"})
+	common.AssertStatusCode(t, resp, http.StatusUnauthorized)
+
+	// Update replaces the metadata and keeps the credentials
+	resp, body = its.MakeRequest(t, "PUT", path, map[string]interface{}{
+		"client_id":     info.ClientID,
+		"client_name":   "Renamed Client",
+		"redirect_uris": []string{"https://managed.integration.test/new-callback"},
+		"grant_types":   []string{"authorization_code", "password"},
+	}, auth)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	var updated clientInformation
+	common.AssertJSONResponse(t, body, &updated)
+	if updated.ClientName != "Renamed Client" || updated.ClientSecret != info.ClientSecret {
+		t.Errorf("❌ REGISTRATION UPDATE FAILED: Unexpected client information %s", string(body))
+	}
+
+	// The updated grant types take effect
+	resp, _ = its.MakeRequest(t, "POST", "/token", url.Values{
+		"grant_type": {"password"},
+		"username":   {"alice"},
+		"password":   {"alice-password"},
+	}, basicAuthHeader(info.ClientID, info.ClientSecret))
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	// Delete
+	resp, _ = its.MakeRequest(t, "DELETE", path, nil, auth)
+	common.AssertStatusCode(t, resp, http.StatusNoContent)
+
+	resp, _ = its.MakeRequest(t, "GET", path, nil, auth)
+	common.AssertStatusCode(t, resp, http.StatusUnauthorized)
+
+	resp, body = its.MakeRequest(t, "GET", "/authorize?"+url.Values{
+		"response_type": {"code"},
+		"client_id":     {info.ClientID},
+	}.Encode(), nil, nil)
+	common.AssertStatusCode(t, resp, http.StatusBadRequest)
+	common.AssertResponseContains(t, body, "Unknown client")
+
+	t.Log("✅ Client registration management passed")
+}
+
+// TestClientRegistrationInvalidMetadata tests metadata validation errors (RFC 7591 section 3.2.2)
+func TestClientRegistrationInvalidMetadata(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	testCases := []struct {
+		name          string
+		metadata      map[string]interface{}
+		expectedError string
+	}{
+		{
+			name:          "missing redirect URIs",
+			metadata:      map[string]interface{}{"client_name": "No Redirects"},
+			expectedError: "invalid_redirect_uri",
+		},
+		{
+			name:          "relative redirect URI",
+			metadata:      map[string]interface{}{"redirect_uris": []string{"/callback"}},
+			expectedError: "invalid_redirect_uri",
+		},
+		{
+			name: "unsupported grant type",
+			metadata: map[string]interface{}{
+				"grant_types": []string{"implicit"},
+			},
+			expectedError: "invalid_client_metadata",
+		},
+		{
+			name: "jwks_uri outside the fetch hosts",
+			metadata: map[string]interface{}{
+				"redirect_uris": []string{"https://example.test/cb"},
+				"jwks_uri":      "http://169.254.169.254/latest/meta-data",
+			},
+			expectedError: "invalid_client_metadata",
+		},
+		{
+			name: "unsupported auth method",
+			metadata: map[string]interface{}{
+				"redirect_uris":              []string{"https://example.test/cb"},
+				"token_endpoint_auth_method": "client_secret_jwt",
+			},
+			expectedError: "invalid_client_metadata",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, body := its.MakeRequest(t, "POST", "/register", tc.metadata, nil)
+			common.AssertStatusCode(t, resp, http.StatusBadRequest)
+			common.AssertResponseContains(t, body, tc.expectedError)
+		})
+	}
+
+	t.Log("✅ Client registration metadata validation passed")
+}
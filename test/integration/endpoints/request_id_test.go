@@ -0,0 +1,34 @@
+package endpoints
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/shogotsuneto/jwks-mock-api/test/integration/common"
+)
+
+// TestRequestID tests that request IDs are propagated from X-Request-ID or generated
+func TestRequestID(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	resp, _ := its.MakeRequest(t, "GET", "/health", nil, map[string]string{"X-Request-ID": "integration-req-1"})
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	if got := resp.Header.Get("X-Request-ID"); got != "integration-req-1" {
+		t.Errorf("❌ REQUEST ID FAILED: expected X-Request-ID integration-req-1, got %q", got)
+	}
+
+	resp, _ = its.MakeRequest(t, "GET", "/health", nil, nil)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	generated := resp.Header.Get("X-Request-ID")
+	if generated == "" {
+		t.Fatal("❌ REQUEST ID FAILED: expected a generated X-Request-ID")
+	}
+
+	resp, _ = its.MakeRequest(t, "GET", "/health", nil, nil)
+	if resp.Header.Get("X-Request-ID") == generated {
+		t.Errorf("❌ REQUEST ID FAILED: expected a new X-Request-ID per request, got %q twice", generated)
+	}
+
+	t.Log("✅ Request ID passed")
+}
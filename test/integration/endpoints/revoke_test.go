@@ -0,0 +1,105 @@
+package endpoints
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/shogotsuneto/jwks-mock-api/test/integration/common"
+)
+
+// introspectToken introspects a token and returns the parsed response
+func introspectToken(t *testing.T, its *common.IntegrationTestSuite, token string) common.IntrospectionResponse {
+	t.Helper()
+
+	formData := url.Values{"token": {token}}
+	resp, body := its.MakeRequest(t, "POST", "/introspect", formData, map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	})
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	var introspectResp common.IntrospectionResponse
+	common.AssertJSONResponse(t, body, &introspectResp)
+	return introspectResp
+}
+
+// revokeToken revokes a token through the revocation endpoint
+func revokeToken(t *testing.T, its *common.IntegrationTestSuite, token string) {
+	t.Helper()
+
+	formData := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	resp, _ := its.MakeRequest(t, "POST", "/revoke", formData, map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	})
+	common.AssertStatusCode(t, resp, http.StatusOK)
+}
+
+// TestTokenRevocation tests that revoked tokens are reported inactive by introspection
+func TestTokenRevocation(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	// Token with a jti and token without one (recorded by hash)
+	withJTI := generateUserInfoToken(t, its, "revocation-user", "openid")
+	resp, body := its.MakeRequest(t, "POST", "/generate-token", map[string]interface{}{
+		"claims": map[string]interface{}{"sub": "revocation-user", "jti": "revocation-test-jti"},
+	}, nil)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	var tokenResp common.TokenResponse
+	common.AssertJSONResponse(t, body, &tokenResp)
+
+	for name, token := range map[string]string{"token hash": withJTI, "jti": tokenResp.Token} {
+		if !introspectToken(t, its, token).Active {
+			t.Fatalf("❌ REVOCATION FAILED: Expected %s token to be active before revocation", name)
+		}
+
+		revokeToken(t, its, token)
+
+		if introspectToken(t, its, token).Active {
+			t.Errorf("❌ REVOCATION FAILED: Expected %s token to be inactive after revocation", name)
+		}
+	}
+
+	// Revoking an invalid token is not an error (RFC 7009)
+	revokeToken(t, its, "not-a-jwt")
+
+	// Missing token parameter is an invalid request
+	resp, body = its.MakeRequest(t, "POST", "/revoke", url.Values{}, map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	})
+	common.AssertStatusCode(t, resp, http.StatusBadRequest)
+	common.AssertResponseContains(t, body, "invalid_request")
+
+	t.Log("✅ Token revocation test passed")
+}
+
+// TestRevokedTokensManagement tests listing and clearing the revocation store
+func TestRevokedTokensManagement(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	resp, body := its.MakeRequest(t, "POST", "/generate-token", map[string]interface{}{
+		"claims": map[string]interface{}{"sub": "revocation-admin-user", "jti": "revocation-admin-jti"},
+	}, nil)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	var tokenResp common.TokenResponse
+	common.AssertJSONResponse(t, body, &tokenResp)
+
+	revokeToken(t, its, tokenResp.Token)
+
+	resp, body = its.MakeRequest(t, "GET", "/revoked-tokens", nil, nil)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	common.AssertContentType(t, resp, "application/json")
+	common.AssertResponseContains(t, body, "revocation-admin-jti")
+
+	resp, body = its.MakeRequest(t, "DELETE", "/revoked-tokens", nil, nil)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	common.AssertResponseContains(t, body, `"success":true`)
+
+	// Clearing the store makes the token active again
+	if !introspectToken(t, its, tokenResp.Token).Active {
+		t.Error("❌ REVOCATION FAILED: Expected token to be active after clearing the revocation store")
+	}
+
+	t.Log("✅ Revocation store management test passed")
+}
@@ -0,0 +1,96 @@
+package endpoints
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/shogotsuneto/jwks-mock-api/test/integration/common"
+)
+
+// TestTenants tests creating a tenant at runtime and using its own issuer, audience and keys
+func TestTenants(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	resp, body := its.MakeRequest(t, "POST", "/tenants", map[string]interface{}{
+		"name":     "orders-service",
+		"audience": "orders-api",
+		"key_ids":  []string{"orders-key-1"},
+	}, nil)
+	common.AssertStatusCode(t, resp, http.StatusCreated)
+	t.Cleanup(func() { its.MakeRequest(t, "DELETE", "/tenants/orders-service", nil, nil) })
+
+	tenantIssuer := integrationIssuer + "/tenants/orders-service"
+	var tenant struct {
+		Issuer string   `json:"issuer"`
+		KeyIDs []string `json:"key_ids"`
+	}
+	common.AssertJSONResponse(t, body, &tenant)
+	if tenant.Issuer != tenantIssuer {
+		t.Errorf("❌ TENANT FAILED: Expected issuer %s, got %s", tenantIssuer, tenant.Issuer)
+	}
+
+	// The tenant publishes only its own keys
+	resp, body = its.MakeRequest(t, "GET", "/tenants/orders-service/.well-known/jwks.json", nil, nil)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	var jwks common.JWKSResponse
+	common.AssertJSONResponse(t, body, &jwks)
+	if len(jwks.Keys) != 1 || jwks.Keys[0].KeyID != "orders-key-1" {
+		t.Errorf("❌ TENANT FAILED: Expected only orders-key-1 in the tenant JWKS, got %+v", jwks.Keys)
+	}
+
+	resp, body = its.MakeRequest(t, "GET", "/tenants/orders-service/.well-known/openid-configuration", nil, nil)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	common.AssertResponseContains(t, body, `"issuer":"`+tenantIssuer+`"`, tenantIssuer+"/.well-known/jwks.json")
+
+	// Tokens carry the tenant issuer and audience and are signed with the tenant key
+	resp, body = its.MakeRequest(t, "POST", "/tenants/orders-service/generate-token", map[string]interface{}{
+		"claims": map[string]interface{}{"sub": "tenant-user"},
+	}, nil)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	var tokenResp common.TokenResponse
+	common.AssertJSONResponse(t, body, &tokenResp)
+	token := common.AssertValidJWT(t, tokenResp.Token)
+	common.AssertJWTClaims(t, token, map[string]interface{}{"iss": tenantIssuer, "aud": "orders-api"})
+	if token.Header["kid"] != "orders-key-1" {
+		t.Errorf("❌ TENANT FAILED: Expected kid orders-key-1, got %v", token.Header["kid"])
+	}
+
+	// Each issuer only accepts its own tokens
+	if !introspectTenantToken(t, its, "/tenants/orders-service/introspect", tokenResp.Token) {
+		t.Error("❌ TENANT FAILED: Expected the tenant to accept its own token")
+	}
+	if introspectTenantToken(t, its, "/introspect", tokenResp.Token) {
+		t.Error("❌ TENANT FAILED: Expected the top-level issuer to reject the tenant token")
+	}
+
+	resp, body = its.MakeRequest(t, "GET", "/tenants", nil, nil)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	common.AssertResponseContains(t, body, "orders-service")
+
+	resp, _ = its.MakeRequest(t, "POST", "/tenants", map[string]interface{}{"name": "orders-service"}, nil)
+	common.AssertStatusCode(t, resp, http.StatusConflict)
+
+	resp, _ = its.MakeRequest(t, "DELETE", "/tenants/orders-service", nil, nil)
+	common.AssertStatusCode(t, resp, http.StatusNoContent)
+	resp, _ = its.MakeRequest(t, "GET", "/tenants/orders-service/.well-known/jwks.json", nil, nil)
+	common.AssertStatusCode(t, resp, http.StatusNotFound)
+
+	t.Log("✅ Tenants passed")
+}
+
+// introspectTenantToken reports whether an introspection endpoint considers the token active
+func introspectTenantToken(t *testing.T, its *common.IntegrationTestSuite, path, token string) bool {
+	t.Helper()
+
+	formData := url.Values{"token": {token}}
+	resp, body := its.MakeRequest(t, "POST", path, formData, map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	})
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	var introspection common.IntrospectionResponse
+	common.AssertJSONResponse(t, body, &introspection)
+	return introspection.Active
+}
@@ -0,0 +1,153 @@
+package endpoints
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shogotsuneto/jwks-mock-api/test/integration/common"
+)
+
+const (
+	tokenExchangeGrant = "urn:ietf:params:oauth:grant-type:token-exchange"
+	accessTokenType    = "urn:ietf:params:oauth:token-type:access_token"
+)
+
+// postTokenRequest sends a form-encoded request to the token endpoint
+func postTokenRequest(t *testing.T, its *common.IntegrationTestSuite, form url.Values) (*http.Response, []byte) {
+	t.Helper()
+
+	return its.MakeRequest(t, "POST", "/token", form, map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	})
+}
+
+// TestTokenExchange tests exchanging a user token for a downstream token (RFC 8693)
+func TestTokenExchange(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	subjectToken := generateUserInfoToken(t, its, "exchange-user", "orders:read orders:write")
+	actorToken := generateUserInfoToken(t, its, "orders-service", "")
+
+	resp, body := postTokenRequest(t, its, url.Values{
+		"grant_type":         {tokenExchangeGrant},
+		"subject_token":      {subjectToken},
+		"subject_token_type": {accessTokenType},
+		"actor_token":        {actorToken},
+		"actor_token_type":   {accessTokenType},
+		"audience":           {"inventory-service"},
+		"scope":              {"orders:read"},
+	})
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	common.AssertContentType(t, resp, "application/json")
+
+	var tokenResp common.OAuthTokenResponse
+	common.AssertJSONResponse(t, body, &tokenResp)
+
+	if tokenResp.IssuedTokenType != accessTokenType {
+		t.Errorf("❌ TOKEN EXCHANGE FAILED: Expected issued_token_type %s, got %s", accessTokenType, tokenResp.IssuedTokenType)
+	}
+	if tokenResp.TokenType != "Bearer" {
+		t.Errorf("❌ TOKEN EXCHANGE FAILED: Expected token_type Bearer, got %s", tokenResp.TokenType)
+	}
+
+	token := common.AssertValidJWT(t, tokenResp.AccessToken)
+	common.AssertJWTClaims(t, token, map[string]interface{}{
+		"sub":   "exchange-user",
+		"aud":   "inventory-service",
+		"scope": "orders:read",
+	})
+
+	act, ok := token.Claims.(jwt.MapClaims)["act"].(map[string]interface{})
+	if !ok || act["sub"] != "orders-service" {
+		t.Fatalf("❌ TOKEN EXCHANGE FAILED: Expected act.sub 'orders-service', got %v", token.Claims.(jwt.MapClaims)["act"])
+	}
+
+	// A second hop nests the prior delegation chain
+	secondActor := generateUserInfoToken(t, its, "inventory-service", "")
+	resp, body = postTokenRequest(t, its, url.Values{
+		"grant_type":         {tokenExchangeGrant},
+		"subject_token":      {tokenResp.AccessToken},
+		"subject_token_type": {accessTokenType},
+		"actor_token":        {secondActor},
+		"actor_token_type":   {accessTokenType},
+		"audience":           {"warehouse-service"},
+	})
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	common.AssertJSONResponse(t, body, &tokenResp)
+
+	token = common.AssertValidJWT(t, tokenResp.AccessToken)
+	act = token.Claims.(jwt.MapClaims)["act"].(map[string]interface{})
+	nested, ok := act["act"].(map[string]interface{})
+	if act["sub"] != "inventory-service" || !ok || nested["sub"] != "orders-service" {
+		t.Errorf("❌ TOKEN EXCHANGE FAILED: Expected nested delegation chain, got %v", act)
+	}
+
+	// The exchanged token is valid for introspection
+	if !introspectToken(t, its, tokenResp.AccessToken).Active {
+		t.Error("❌ TOKEN EXCHANGE FAILED: Expected exchanged token to be active")
+	}
+
+	t.Log("✅ Token exchange test passed")
+}
+
+// TestTokenExchangeErrors tests token exchange error responses
+func TestTokenExchangeErrors(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	subjectToken := generateUserInfoToken(t, its, "exchange-user", "orders:read")
+
+	tests := []struct {
+		name          string
+		form          url.Values
+		expectedError string
+	}{
+		{
+			name:          "unsupported grant type",
+			form:          url.Values{"grant_type": {"unknown"}},
+			expectedError: "unsupported_grant_type",
+		},
+		{
+			name:          "missing subject token",
+			form:          url.Values{"grant_type": {tokenExchangeGrant}, "subject_token_type": {accessTokenType}},
+			expectedError: "invalid_request",
+		},
+		{
+			name: "invalid subject token",
+			form: url.Values{
+				"grant_type":         {tokenExchangeGrant},
+				"subject_token":      {"not-a-jwt"},
+				"subject_token_type": {accessTokenType},
+			},
+			expectedError: "invalid_request",
+		},
+		{
+			name: "scope broader than subject token",
+			form: url.Values{
+				"grant_type":         {tokenExchangeGrant},
+				"subject_token":      {subjectToken},
+				"subject_token_type": {accessTokenType},
+				"scope":              {"orders:read orders:delete"},
+			},
+			expectedError: "invalid_scope",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := postTokenRequest(t, its, tt.form)
+			common.AssertStatusCode(t, resp, http.StatusBadRequest)
+
+			var errResp common.OAuthErrorResponse
+			common.AssertJSONResponse(t, body, &errResp)
+			if errResp.Error != tt.expectedError {
+				t.Errorf("❌ TOKEN EXCHANGE FAILED: Expected error %s, got %s", tt.expectedError, errResp.Error)
+			}
+		})
+	}
+
+	t.Log("✅ Token exchange error handling test passed")
+}
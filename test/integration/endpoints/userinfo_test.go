@@ -0,0 +1,132 @@
+package endpoints
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/shogotsuneto/jwks-mock-api/test/integration/common"
+)
+
+// generateUserInfoToken generates an access token for the configured userinfo test user
+func generateUserInfoToken(t *testing.T, its *common.IntegrationTestSuite, sub, scope string) string {
+	t.Helper()
+
+	tokenReq := map[string]interface{}{
+		"claims": map[string]interface{}{
+			"sub":   sub,
+			"scope": scope,
+		},
+	}
+
+	resp, body := its.MakeRequest(t, "POST", "/generate-token", tokenReq, nil)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	var tokenResp common.TokenResponse
+	common.AssertJSONResponse(t, body, &tokenResp)
+
+	return tokenResp.Token
+}
+
+// TestUserInfo tests the UserInfo endpoint with a bearer token
+func TestUserInfo(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	token := generateUserInfoToken(t, its, "userinfo-test-user", "openid profile roles")
+
+	resp, body := its.MakeRequest(t, "GET", "/userinfo", nil, map[string]string{
+		"Authorization": "Bearer " + token,
+	})
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	common.AssertContentType(t, resp, "application/json")
+
+	var userInfo map[string]interface{}
+	common.AssertJSONResponse(t, body, &userInfo)
+
+	if userInfo["sub"] != "userinfo-test-user" {
+		t.Errorf("❌ USERINFO FAILED: Expected sub 'userinfo-test-user', got %v", userInfo["sub"])
+	}
+	if userInfo["name"] != "UserInfo Test User" {
+		t.Errorf("❌ USERINFO FAILED: Expected profile claim name, got %v", userInfo["name"])
+	}
+	if userInfo["department"] != "Engineering" {
+		t.Errorf("❌ USERINFO FAILED: Expected custom claim department, got %v", userInfo["department"])
+	}
+	if roles, ok := userInfo["roles"].([]interface{}); !ok || len(roles) != 2 {
+		t.Errorf("❌ USERINFO FAILED: Expected 2 roles, got %v", userInfo["roles"])
+	}
+
+	// Claims for scopes that were not granted must be filtered out
+	if _, ok := userInfo["email"]; ok {
+		t.Errorf("❌ USERINFO FAILED: Expected email to be filtered without the email scope")
+	}
+	if _, ok := userInfo["groups"]; ok {
+		t.Errorf("❌ USERINFO FAILED: Expected groups to be filtered without the groups scope")
+	}
+
+	t.Log("✅ UserInfo test passed")
+}
+
+// TestUserInfoFormPost tests the UserInfo endpoint with the token in a form body
+func TestUserInfoFormPost(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	token := generateUserInfoToken(t, its, "userinfo-test-user", "openid email")
+
+	formData := url.Values{"access_token": {token}}
+	resp, body := its.MakeRequest(t, "POST", "/userinfo", formData, map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	})
+	common.AssertStatusCode(t, resp, http.StatusOK)
+
+	var userInfo map[string]interface{}
+	common.AssertJSONResponse(t, body, &userInfo)
+
+	if userInfo["email"] != "userinfo@integration.test" {
+		t.Errorf("❌ USERINFO FAILED: Expected email claim, got %v", userInfo["email"])
+	}
+	if _, ok := userInfo["name"]; ok {
+		t.Errorf("❌ USERINFO FAILED: Expected name to be filtered without the profile scope")
+	}
+
+	t.Log("✅ UserInfo form post test passed")
+}
+
+// TestUserInfoErrors tests UserInfo error responses
+func TestUserInfoErrors(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	// Missing token
+	resp, _ := its.MakeRequest(t, "GET", "/userinfo", nil, nil)
+	common.AssertStatusCode(t, resp, http.StatusUnauthorized)
+	if resp.Header.Get("WWW-Authenticate") == "" {
+		t.Error("❌ USERINFO FAILED: Expected WWW-Authenticate header for missing token")
+	}
+
+	// Invalid token
+	resp, body := its.MakeRequest(t, "GET", "/userinfo", nil, map[string]string{
+		"Authorization": "Bearer not-a-jwt",
+	})
+	common.AssertStatusCode(t, resp, http.StatusUnauthorized)
+	common.AssertResponseContains(t, body, "invalid_token")
+
+	// Token without the openid scope
+	token := generateUserInfoToken(t, its, "userinfo-test-user", "profile")
+	resp, body = its.MakeRequest(t, "GET", "/userinfo", nil, map[string]string{
+		"Authorization": "Bearer " + token,
+	})
+	common.AssertStatusCode(t, resp, http.StatusForbidden)
+	common.AssertResponseContains(t, body, "insufficient_scope")
+
+	// Unknown user
+	token = generateUserInfoToken(t, its, "unknown-user", "openid")
+	resp, _ = its.MakeRequest(t, "GET", "/userinfo", nil, map[string]string{
+		"Authorization": "Bearer " + token,
+	})
+	common.AssertStatusCode(t, resp, http.StatusUnauthorized)
+
+	t.Log("✅ UserInfo error handling test passed")
+}
@@ -3,6 +3,7 @@ package endpoints
 import (
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/shogotsuneto/jwks-mock-api/test/integration/common"
 )
@@ -24,4 +25,51 @@ func TestJWKS(t *testing.T) {
 	common.AssertValidJWKS(t, &jwks)
 	
 	t.Logf("✅ JWKS validation passed with %d keys", len(jwks.Keys))
-}
\ No newline at end of file
+}
+// TestJWKSConditionalRequests tests the JWKS caching headers and 304 responses. It uses a
+// tenant so adding a key does not affect other tests reading the top-level key set.
+func TestJWKSConditionalRequests(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	resp, _ := its.MakeRequest(t, "POST", "/tenants", map[string]interface{}{"name": "jwks-cache-tenant"}, nil)
+	common.AssertStatusCode(t, resp, http.StatusCreated)
+	t.Cleanup(func() { its.MakeRequest(t, "DELETE", "/tenants/jwks-cache-tenant", nil, nil) })
+	jwksPath := "/tenants/jwks-cache-tenant/.well-known/jwks.json"
+
+	resp, _ = its.MakeRequest(t, "GET", jwksPath, nil, nil)
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	etag := resp.Header.Get("ETag")
+	lastModified := resp.Header.Get("Last-Modified")
+	if etag == "" || lastModified == "" {
+		t.Fatalf("❌ JWKS CACHE FAILED: Expected ETag and Last-Modified, got %q and %q", etag, lastModified)
+	}
+	if cacheControl := resp.Header.Get("Cache-Control"); cacheControl != "public, max-age=3600" {
+		t.Errorf("❌ JWKS CACHE FAILED: Expected the default Cache-Control, got %q", cacheControl)
+	}
+
+	resp, body := its.MakeRequest(t, "GET", jwksPath, nil, map[string]string{"If-None-Match": etag})
+	common.AssertStatusCode(t, resp, http.StatusNotModified)
+	if len(body) != 0 {
+		t.Errorf("❌ JWKS CACHE FAILED: Expected an empty 304 body, got %s", body)
+	}
+	resp, _ = its.MakeRequest(t, "GET", jwksPath, nil, map[string]string{"If-Modified-Since": lastModified})
+	common.AssertStatusCode(t, resp, http.StatusNotModified)
+
+	// Adding a key changes the validators
+	resp, _ = its.MakeRequest(t, "POST", "/tenants/jwks-cache-tenant/keys", map[string]interface{}{"kid": "rotated-key"}, nil)
+	common.AssertStatusCode(t, resp, http.StatusCreated)
+
+	resp, body = its.MakeRequest(t, "GET", jwksPath, nil, map[string]string{"If-None-Match": etag})
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	common.AssertResponseContains(t, body, "rotated-key")
+	if resp.Header.Get("ETag") == etag {
+		t.Error("❌ JWKS CACHE FAILED: Expected a new ETag after adding a key")
+	}
+	// Changes within the same second keep Last-Modified, which never runs ahead of the clock
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err != nil || modified.After(time.Now()) {
+		t.Errorf("❌ JWKS CACHE FAILED: Expected a Last-Modified not after now, got %q", resp.Header.Get("Last-Modified"))
+	}
+
+	t.Log("✅ JWKS conditional requests passed")
+}
@@ -0,0 +1,32 @@
+package endpoints
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/shogotsuneto/jwks-mock-api/test/integration/common"
+)
+
+// TestOpenIDConfiguration tests the OpenID Connect discovery endpoint
+func TestOpenIDConfiguration(t *testing.T) {
+	its := common.NewIntegrationTestSuite()
+	its.WaitForAPI(t)
+
+	resp, body := its.MakeRequest(t, "GET", "/.well-known/openid-configuration", nil, nil)
+
+	common.AssertStatusCode(t, resp, http.StatusOK)
+	common.AssertContentType(t, resp, "application/json")
+
+	var discovery map[string]interface{}
+	common.AssertJSONResponse(t, body, &discovery)
+
+	if discovery["issuer"] != "http://jwks-api:3000" {
+		t.Errorf("❌ DISCOVERY FAILED: Expected issuer 'http://jwks-api:3000', got %v", discovery["issuer"])
+	}
+
+	if discovery["jwks_uri"] != "http://jwks-api:3000/.well-known/jwks.json" {
+		t.Errorf("❌ DISCOVERY FAILED: Expected jwks_uri below the issuer, got %v", discovery["jwks_uri"])
+	}
+
+	t.Log("✅ OpenID configuration test passed")
+}